@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+
+	"github.com/Allexy/fishes/internal/ast"
+	"github.com/Allexy/fishes/internal/interp"
+	"github.com/Allexy/fishes/internal/parser"
+	"github.com/Allexy/fishes/internal/printer"
+	"github.com/Allexy/fishes/internal/tokenizer"
+)
+
+// Parses flags of command and reads its sources
+func (c *cli) prepare(flags *flag.FlagSet, args []string) ([]source, int) {
+	if err := flags.Parse(args); err != nil {
+		return nil, exitUsage
+	}
+	sources, err := c.readSources(flags.Args())
+	if err != nil {
+		c.printError(err)
+		return nil, exitUsage
+	}
+	return sources, exitOK
+}
+
+func tokenize(src source, options ...tokenizer.Option) (tokenizer.TokenWalker, error) {
+	return tokenizer.NewTokenizer(bytes.NewReader(src.data), src.name, options...).Tokenize()
+}
+
+func parse(src source, options ...tokenizer.Option) (*ast.Program, error) {
+	tw, err := tokenize(src, options...)
+	if err != nil {
+		return nil, err
+	}
+	return parser.NewParser(tw).Parse()
+}
+
+// Calls f for every source, source errors are printed and do not stop processing of next sources
+func (c *cli) each(sources []source, f func(src source) error) int {
+	code := exitOK
+	for _, src := range sources {
+		if len(sources) > 1 {
+			fmt.Fprintf(c.stdout, "==> %s <==\n", src.name)
+		}
+		if err := f(src); err != nil {
+			c.printError(err)
+			code = exitFailure
+		}
+	}
+	return code
+}
+
+// fishes tokens [-trivia] sources...
+func (c *cli) tokensCommand(args []string) int {
+	flags := c.flags("tokens")
+	trivia := flags.Bool("trivia", false, "print comments and white spaces")
+	sources, code := c.prepare(flags, args)
+	if code != exitOK {
+		return code
+	}
+	var options []tokenizer.Option
+	if *trivia {
+		options = append(options, tokenizer.WithTrivia())
+	}
+	return c.each(sources, func(src source) error {
+		tw, err := tokenize(src, options...)
+		if err != nil {
+			return err
+		}
+		for tw.Next() {
+			t := tw.Get(0)
+			for _, l := range t.Leading {
+				fmt.Fprintln(c.stdout, l.Describe(tw.FileSet()))
+			}
+			fmt.Fprintln(c.stdout, t.Describe(tw.FileSet()))
+			tw.Move(1)
+		}
+		return nil
+	})
+}
+
+// fishes parse sources...
+func (c *cli) parseCommand(args []string) int {
+	sources, code := c.prepare(c.flags("parse"), args)
+	if code != exitOK {
+		return code
+	}
+	return c.each(sources, func(src source) error {
+		program, err := parse(src)
+		if err != nil {
+			return err
+		}
+		return ast.Fprint(c.stdout, program)
+	})
+}
+
+// fishes fmt sources...
+// Comments are kept so source is tokenized with trivia
+func (c *cli) fmtCommand(args []string) int {
+	sources, code := c.prepare(c.flags("fmt"), args)
+	if code != exitOK {
+		return code
+	}
+	return c.each(sources, func(src source) error {
+		program, err := parse(src, tokenizer.WithTrivia())
+		if err != nil {
+			return err
+		}
+		return printer.Fprint(c.stdout, program)
+	})
+}
+
+// fishes check sources...
+// Prints nothing for valid sources
+func (c *cli) checkCommand(args []string) int {
+	sources, code := c.prepare(c.flags("check"), args)
+	if code != exitOK {
+		return code
+	}
+	code = exitOK
+	for _, src := range sources {
+		if _, err := parse(src); err != nil {
+			c.printError(err)
+			code = exitFailure
+		}
+	}
+	return code
+}
+
+// fishes run sources...
+// Every script runs in its own interpreter, execution stops at the first uncaught exception
+func (c *cli) runCommand(args []string) int {
+	sources, code := c.prepare(c.flags("run"), args)
+	if code != exitOK {
+		return code
+	}
+	for _, src := range sources {
+		program, err := parse(src)
+		if err != nil {
+			c.printError(err)
+			return exitFailure
+		}
+		if _, err := interp.NewInterpreter(c.stdout).Run(program); err != nil {
+			c.printError(err)
+			return exitFailure
+		}
+	}
+	return exitOK
+}
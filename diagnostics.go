@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Allexy/fishes/internal/interp"
+)
+
+// Errors of tokenizer and parser
+type sourceError interface {
+	FileName() string
+	Message() string
+	Line() uint32
+	Col() uint32
+}
+
+// Writes error to stderr, errors related to source are shown with the line where they happened
+func (c *cli) printError(err error) {
+	var se sourceError
+	var e *interp.Exception
+	switch {
+	case errors.As(err, &e):
+		fmt.Fprintf(c.stderr, "%v: %s (code %d): %s\n", e.Pos, interp.CodeName(e.Code), e.Code, e.Message)
+		c.printLine(e.Pos.SourceName, e.Pos.Line, e.Pos.Col)
+		for _, f := range e.Stack {
+			fmt.Fprintf(c.stderr, "\t%v\n", f)
+		}
+	case errors.As(err, &se):
+		fmt.Fprintf(c.stderr, "%s:%d:%d: %s\n", se.FileName(), se.Line(), se.Col(), se.Message())
+		c.printLine(se.FileName(), se.Line(), se.Col())
+	default:
+		fmt.Fprintf(c.stderr, "%s\n", err)
+	}
+}
+
+// Writes source line and marks column
+func (c *cli) printLine(name string, line, col uint32) {
+	data, ok := c.sources[name]
+	if !ok || line == 0 {
+		return
+	}
+	lines := strings.Split(string(data), "\n")
+	if int(line) > len(lines) {
+		return
+	}
+	text := strings.TrimRight(lines[line-1], "\r")
+	// keep tabs so marker is aligned with the text above
+	var marker strings.Builder
+	for i, r := range []rune(text) {
+		if uint32(i+1) >= col {
+			break
+		}
+		if r == '\t' {
+			marker.WriteRune('\t')
+		} else {
+			marker.WriteRune(' ')
+		}
+	}
+	fmt.Fprintf(c.stderr, "    %s\n    %s^\n", text, marker.String())
+}
@@ -0,0 +1,405 @@
+package ast
+
+import (
+	"fmt"
+	"math/big"
+	"unicode/utf8"
+
+	"github.com/Allexy/fishes/internal/tokenizer"
+)
+
+// Position of a node in the source
+type Position struct {
+	SourceName string
+	Line, Col  uint32
+}
+
+// Creates position from the token, it is resolved by file set
+func PositionOf(fileSet *tokenizer.FileSet, t *tokenizer.Token) Position {
+	return positionFrom(fileSet.Position(t.Pos))
+}
+
+// Creates position immediately after the token
+func EndOf(fileSet *tokenizer.FileSet, t *tokenizer.Token) Position {
+	if t.End != tokenizer.NoPos {
+		return positionFrom(fileSet.EndPosition(t.End))
+	}
+	// token was not created by tokenizer, its width is estimated by text
+	p := PositionOf(fileSet, t)
+	width := uint32(utf8.RuneCountInString(t.Text))
+	switch t.Token {
+	case tokenizer.TokenString:
+		width += 2 // quote marks
+	case tokenizer.TokenVariable:
+		width += 1 // $ sign
+	case tokenizer.TokenBOF, tokenizer.TokenEOF:
+		width = 0
+	}
+	p.Col += width
+	return p
+}
+
+func positionFrom(p tokenizer.Position) Position {
+	return Position{SourceName: p.Filename, Line: p.Line, Col: p.Col}
+}
+
+// Returns true if position is set
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.SourceName, p.Line, p.Col)
+}
+
+// All node types implement the Node interface
+type Node interface {
+	Pos() Position // position of the first token belonging to the node
+	End() Position // position immediately after the node
+}
+
+// All expression nodes implement the Expr interface
+type Expr interface {
+	Node
+	exprNode()
+}
+
+// All statement nodes implement the Stmt interface
+type Stmt interface {
+	Node
+	stmtNode()
+}
+
+// Expressions
+type (
+	// Numerical literal, text is already normalized and value parsed by tokenizer
+	NumberLit struct {
+		Start  Position
+		Text   string
+		Raw    string // source spelling such as 0xFF or 1_000, empty if it is not known
+		Value  *big.Rat
+		Finish Position
+	}
+
+	// String literal, value is already unescaped by tokenizer
+	StringLit struct {
+		Start  Position
+		Value  string
+		Raw    bool // literal is written in back quotes, it is known only if tokens have trivia
+		Finish Position
+	}
+
+	// "text ${X} text", there is one more string part than embedded expressions
+	InterpolatedString struct {
+		Start  Position
+		Parts  []*StringLit
+		Exprs  []Expr
+		Finish Position
+	}
+
+	// true / false
+	LogicLit struct {
+		Start  Position
+		Value  bool
+		Finish Position
+	}
+
+	// null
+	NullLit struct {
+		Start  Position
+		Finish Position
+	}
+
+	// Bare word, e.g. function name in call expression
+	Ident struct {
+		Start  Position
+		Name   string
+		Finish Position
+	}
+
+	// $name
+	Variable struct {
+		Start  Position
+		Name   string
+		Finish Position
+	}
+
+	// @name
+	FuncRef struct {
+		Start  Position
+		Name   string
+		Finish Position
+	}
+
+	// func($a, $b) { ... }
+	FuncLit struct {
+		Start  Position
+		Params []*Variable
+		Body   *BlockStmt
+	}
+
+	// ( X )
+	ParenExpr struct {
+		Start  Position
+		X      Expr
+		Finish Position
+	}
+
+	// Fun(Args...)
+	CallExpr struct {
+		Fun    Expr
+		Args   []Expr
+		Finish Position
+	}
+
+	// Op X
+	UnaryExpr struct {
+		Start Position
+		Op    string
+		X     Expr
+	}
+
+	// ++X, --X, X++ or X--
+	IncDecExpr struct {
+		Start   Position
+		Op      string
+		X       *Variable
+		Postfix bool
+		Finish  Position
+	}
+
+	// X Op Y
+	BinaryExpr struct {
+		X     Expr
+		OpPos Position
+		Op    string
+		Y     Expr
+	}
+
+	// Lhs = Rhs, Lhs += Rhs ...
+	AssignExpr struct {
+		Lhs *Variable
+		Op  string
+		Rhs Expr
+	}
+)
+
+func (x *NumberLit) Pos() Position          { return x.Start }
+func (x *StringLit) Pos() Position          { return x.Start }
+func (x *InterpolatedString) Pos() Position { return x.Start }
+func (x *LogicLit) Pos() Position           { return x.Start }
+func (x *NullLit) Pos() Position            { return x.Start }
+func (x *Ident) Pos() Position              { return x.Start }
+func (x *Variable) Pos() Position           { return x.Start }
+func (x *FuncRef) Pos() Position            { return x.Start }
+func (x *FuncLit) Pos() Position            { return x.Start }
+func (x *ParenExpr) Pos() Position          { return x.Start }
+func (x *CallExpr) Pos() Position           { return x.Fun.Pos() }
+func (x *UnaryExpr) Pos() Position          { return x.Start }
+func (x *IncDecExpr) Pos() Position         { return x.Start }
+func (x *BinaryExpr) Pos() Position         { return x.X.Pos() }
+func (x *AssignExpr) Pos() Position         { return x.Lhs.Pos() }
+
+func (x *NumberLit) End() Position          { return x.Finish }
+func (x *StringLit) End() Position          { return x.Finish }
+func (x *InterpolatedString) End() Position { return x.Finish }
+func (x *LogicLit) End() Position           { return x.Finish }
+func (x *NullLit) End() Position            { return x.Finish }
+func (x *Ident) End() Position              { return x.Finish }
+func (x *Variable) End() Position           { return x.Finish }
+func (x *FuncRef) End() Position            { return x.Finish }
+func (x *FuncLit) End() Position            { return x.Body.End() }
+func (x *ParenExpr) End() Position          { return x.Finish }
+func (x *CallExpr) End() Position           { return x.Finish }
+func (x *UnaryExpr) End() Position          { return x.X.End() }
+func (x *IncDecExpr) End() Position         { return x.Finish }
+func (x *BinaryExpr) End() Position         { return x.Y.End() }
+func (x *AssignExpr) End() Position         { return x.Rhs.End() }
+
+func (*NumberLit) exprNode()          {}
+func (*StringLit) exprNode()          {}
+func (*InterpolatedString) exprNode() {}
+func (*LogicLit) exprNode()           {}
+func (*NullLit) exprNode()            {}
+func (*Ident) exprNode()              {}
+func (*Variable) exprNode()           {}
+func (*FuncRef) exprNode()            {}
+func (*FuncLit) exprNode()            {}
+func (*ParenExpr) exprNode()          {}
+func (*CallExpr) exprNode()           {}
+func (*UnaryExpr) exprNode()          {}
+func (*IncDecExpr) exprNode()         {}
+func (*BinaryExpr) exprNode()         {}
+func (*AssignExpr) exprNode()         {}
+
+// Statements
+type (
+	// { Stmts... }
+	BlockStmt struct {
+		Start  Position
+		Stmts  []Stmt
+		Finish Position
+	}
+
+	// Expression followed by semicolon
+	ExprStmt struct {
+		X      Expr
+		Finish Position
+	}
+
+	// @decorator... func Name(Params...) { ... }
+	FuncDecl struct {
+		Start      Position
+		Decorators []*Ident
+		Name       *Ident
+		Params     []*Variable
+		Body       *BlockStmt
+	}
+
+	// return Result; or short form = Result;
+	ReturnStmt struct {
+		Start  Position
+		Result Expr // may be nil
+		Short  bool // true for "=" form
+		Finish Position
+	}
+
+	// throw Value;
+	ThrowStmt struct {
+		Start  Position
+		Value  Expr
+		Finish Position
+	}
+
+	// if(Cond) { ... } else ...
+	IfStmt struct {
+		Start Position
+		Cond  Expr
+		Body  *BlockStmt
+		Else  Stmt // *BlockStmt, *IfStmt or nil
+	}
+
+	// while(Cond) { ... }
+	WhileStmt struct {
+		Start Position
+		Cond  Expr
+		Body  *BlockStmt
+	}
+
+	// do { ... } while(Cond);
+	DoWhileStmt struct {
+		Start  Position
+		Body   *BlockStmt
+		Cond   Expr
+		Finish Position
+	}
+
+	// for(Init; Cond; Post) { ... }
+	ForStmt struct {
+		Start Position
+		Init  Expr // may be nil
+		Cond  Expr // may be nil
+		Post  Expr // may be nil
+		Body  *BlockStmt
+	}
+
+	// switch(Tag) { case(...) { ... } ... }
+	SwitchStmt struct {
+		Start  Position
+		Tag    Expr
+		Cases  []*CaseClause
+		Finish Position
+	}
+
+	// case(Value) { ... }
+	CaseClause struct {
+		Start Position
+		Value Expr
+		Body  *BlockStmt
+	}
+
+	// try { ... } catch($code, $msg) { ... }
+	TryStmt struct {
+		Start Position
+		Body  *BlockStmt
+		Code  *Variable // may be nil
+		Msg   *Variable // may be nil
+		Catch *BlockStmt
+	}
+)
+
+func (s *BlockStmt) Pos() Position   { return s.Start }
+func (s *ExprStmt) Pos() Position    { return s.X.Pos() }
+func (s *FuncDecl) Pos() Position    { return s.Start }
+func (s *ReturnStmt) Pos() Position  { return s.Start }
+func (s *ThrowStmt) Pos() Position   { return s.Start }
+func (s *IfStmt) Pos() Position      { return s.Start }
+func (s *WhileStmt) Pos() Position   { return s.Start }
+func (s *DoWhileStmt) Pos() Position { return s.Start }
+func (s *ForStmt) Pos() Position     { return s.Start }
+func (s *SwitchStmt) Pos() Position  { return s.Start }
+func (s *CaseClause) Pos() Position  { return s.Start }
+func (s *TryStmt) Pos() Position     { return s.Start }
+
+func (s *BlockStmt) End() Position   { return s.Finish }
+func (s *ExprStmt) End() Position    { return s.Finish }
+func (s *FuncDecl) End() Position    { return s.Body.End() }
+func (s *ReturnStmt) End() Position  { return s.Finish }
+func (s *ThrowStmt) End() Position   { return s.Finish }
+func (s *WhileStmt) End() Position   { return s.Body.End() }
+func (s *DoWhileStmt) End() Position { return s.Finish }
+func (s *ForStmt) End() Position     { return s.Body.End() }
+func (s *SwitchStmt) End() Position  { return s.Finish }
+func (s *CaseClause) End() Position  { return s.Body.End() }
+func (s *TryStmt) End() Position     { return s.Catch.End() }
+
+func (s *IfStmt) End() Position {
+	if s.Else != nil {
+		return s.Else.End()
+	}
+	return s.Body.End()
+}
+
+func (*BlockStmt) stmtNode()   {}
+func (*ExprStmt) stmtNode()    {}
+func (*FuncDecl) stmtNode()    {}
+func (*ReturnStmt) stmtNode()  {}
+func (*ThrowStmt) stmtNode()   {}
+func (*IfStmt) stmtNode()      {}
+func (*WhileStmt) stmtNode()   {}
+func (*DoWhileStmt) stmtNode() {}
+func (*ForStmt) stmtNode()     {}
+func (*SwitchStmt) stmtNode()  {}
+func (*TryStmt) stmtNode()     {}
+
+// Root node of parsed source
+type Program struct {
+	Start    Position
+	Stmts    []Stmt     // function declarations and top level statements in order of appearance
+	Comments []*Comment // comments in order of appearance, found only if source is tokenized with trivia
+	Finish   Position
+}
+
+func (p *Program) Pos() Position { return p.Start }
+func (p *Program) End() Position { return p.Finish }
+
+// Comment of source, it is not part of statements and kept only for printing
+type Comment struct {
+	Start  Position
+	Text   string // source text including comment markers
+	Finish Position
+}
+
+func (c *Comment) Pos() Position { return c.Start }
+func (c *Comment) End() Position { return c.Finish }
+
+// Returns function declarations of the program
+func (p *Program) Funcs() []*FuncDecl {
+	funcs := make([]*FuncDecl, 0, len(p.Stmts))
+	for _, s := range p.Stmts {
+		if fd, ok := s.(*FuncDecl); ok {
+			funcs = append(funcs, fd)
+		}
+	}
+	return funcs
+}
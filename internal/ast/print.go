@@ -0,0 +1,67 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Writes tree of nodes, one node per line indented by its depth
+func Fprint(w io.Writer, node Node) error {
+	var err error
+	depth := 0
+	Inspect(node, func(n Node) bool {
+		if n == nil {
+			depth--
+			return false
+		}
+		if err == nil {
+			_, err = fmt.Fprintf(w, "%s%s\n", strings.Repeat("  ", depth), describe(n))
+		}
+		depth++
+		return true
+	})
+	return err
+}
+
+// Returns type of node, its details and position
+func describe(n Node) string {
+	name := strings.TrimPrefix(fmt.Sprintf("%T", n), "*ast.")
+	var detail string
+	switch n := n.(type) {
+	case *NumberLit:
+		detail = n.Text
+	case *StringLit:
+		detail = fmt.Sprintf("%q", n.Value)
+	case *Comment:
+		detail = fmt.Sprintf("%q", n.Text)
+	case *LogicLit:
+		detail = fmt.Sprint(n.Value)
+	case *Ident:
+		detail = n.Name
+	case *Variable:
+		detail = "$" + n.Name
+	case *FuncRef:
+		detail = "@" + n.Name
+	case *UnaryExpr:
+		detail = n.Op
+	case *IncDecExpr:
+		if n.Postfix {
+			detail = "postfix " + n.Op
+		} else {
+			detail = "prefix " + n.Op
+		}
+	case *BinaryExpr:
+		detail = n.Op
+	case *AssignExpr:
+		detail = n.Op
+	case *ReturnStmt:
+		if n.Short {
+			detail = "="
+		}
+	}
+	if detail == "" {
+		return fmt.Sprintf("%s %v", name, n.Pos())
+	}
+	return fmt.Sprintf("%s %s %v", name, detail, n.Pos())
+}
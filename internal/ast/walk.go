@@ -0,0 +1,142 @@
+package ast
+
+import "fmt"
+
+// Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Traverses an AST in depth-first order
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *NumberLit, *StringLit, *LogicLit, *NullLit, *Ident, *Variable, *FuncRef, *Comment:
+		// nothing to do
+	case *InterpolatedString:
+		for i, part := range n.Parts {
+			Walk(v, part)
+			if i < len(n.Exprs) {
+				Walk(v, n.Exprs[i])
+			}
+		}
+	case *FuncLit:
+		walkVariables(v, n.Params)
+		Walk(v, n.Body)
+	case *ParenExpr:
+		Walk(v, n.X)
+	case *CallExpr:
+		Walk(v, n.Fun)
+		for _, a := range n.Args {
+			Walk(v, a)
+		}
+	case *UnaryExpr:
+		Walk(v, n.X)
+	case *IncDecExpr:
+		Walk(v, n.X)
+	case *BinaryExpr:
+		Walk(v, n.X)
+		Walk(v, n.Y)
+	case *AssignExpr:
+		Walk(v, n.Lhs)
+		Walk(v, n.Rhs)
+
+	case *BlockStmt:
+		walkStmts(v, n.Stmts)
+	case *ExprStmt:
+		Walk(v, n.X)
+	case *FuncDecl:
+		for _, d := range n.Decorators {
+			Walk(v, d)
+		}
+		Walk(v, n.Name)
+		walkVariables(v, n.Params)
+		Walk(v, n.Body)
+	case *ReturnStmt:
+		if n.Result != nil {
+			Walk(v, n.Result)
+		}
+	case *ThrowStmt:
+		Walk(v, n.Value)
+	case *IfStmt:
+		Walk(v, n.Cond)
+		Walk(v, n.Body)
+		if n.Else != nil {
+			Walk(v, n.Else)
+		}
+	case *WhileStmt:
+		Walk(v, n.Cond)
+		Walk(v, n.Body)
+	case *DoWhileStmt:
+		Walk(v, n.Body)
+		Walk(v, n.Cond)
+	case *ForStmt:
+		for _, x := range []Expr{n.Init, n.Cond, n.Post} {
+			if x != nil {
+				Walk(v, x)
+			}
+		}
+		Walk(v, n.Body)
+	case *SwitchStmt:
+		Walk(v, n.Tag)
+		for _, c := range n.Cases {
+			Walk(v, c)
+		}
+	case *CaseClause:
+		Walk(v, n.Value)
+		Walk(v, n.Body)
+	case *TryStmt:
+		Walk(v, n.Body)
+		if n.Code != nil {
+			Walk(v, n.Code)
+		}
+		if n.Msg != nil {
+			Walk(v, n.Msg)
+		}
+		Walk(v, n.Catch)
+
+	case *Program:
+		walkStmts(v, n.Stmts)
+		// comments are not attached to statements, they are visited after all of them
+		for _, c := range n.Comments {
+			Walk(v, c)
+		}
+
+	default:
+		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+func walkStmts(v Visitor, stmts []Stmt) {
+	for _, s := range stmts {
+		Walk(v, s)
+	}
+}
+
+func walkVariables(v Visitor, vars []*Variable) {
+	for _, x := range vars {
+		Walk(v, x)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Traverses an AST in depth-first order calling f(node) for each node,
+// children are not visited if f returns false
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
@@ -0,0 +1,197 @@
+package ast_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Allexy/fishes/internal/ast"
+	"github.com/Allexy/fishes/internal/parser"
+	"github.com/Allexy/fishes/internal/tokenizer"
+)
+
+func _parse(t *testing.T, s string, options ...tokenizer.Option) *ast.Program {
+	tw, err := tokenizer.NewTokenizer(strings.NewReader(s), "string", options...).Tokenize()
+	if err != nil {
+		t.Fatalf("Tokenization failed with err: %v", err)
+	}
+	program, err := parser.NewParser(tw).Parse()
+	if err != nil {
+		t.Fatalf("Parsing failed with err: %v", err)
+	}
+	return program
+}
+
+// Compares line and column of position
+func _at(p ast.Position, line, col uint32) bool {
+	return p.Line == line && p.Col == col
+}
+
+func TestInspectSelfTest(t *testing.T) {
+	source, err := os.ReadFile("../../self_test.fs")
+	if err != nil {
+		t.Fatalf("Failed to read self test: %v", err)
+	}
+	program := _parse(t, string(source))
+	funcs, calls := 0, 0
+	ast.Inspect(program, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.FuncDecl:
+			funcs++
+		case *ast.CallExpr:
+			calls++
+		}
+		return true
+	})
+	if funcs != len(program.Funcs()) {
+		t.Errorf("Expected %d function declarations but got %d", len(program.Funcs()), funcs)
+	}
+	if calls < 100 {
+		t.Errorf("Expected more than 100 calls but got %d", calls)
+	}
+}
+
+func TestInspectSkipsChildren(t *testing.T) {
+	program := _parse(t, "func f() { g(); } h();")
+	var names []string
+	ast.Inspect(program, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			names = append(names, id.Name)
+		}
+		_, isFunc := n.(*ast.FuncDecl)
+		return !isFunc
+	})
+	if strings.Join(names, ",") != "h" {
+		t.Errorf("Expected only identifier h to be visited but got %v", names)
+	}
+}
+
+type _depthCounter struct {
+	depth, max int
+}
+
+func (c *_depthCounter) Visit(n ast.Node) ast.Visitor {
+	if n == nil {
+		c.depth--
+		return nil
+	}
+	c.depth++
+	if c.depth > c.max {
+		c.max = c.depth
+	}
+	return c
+}
+
+func TestWalkVisitsNilAfterChildren(t *testing.T) {
+	counter := &_depthCounter{}
+	ast.Walk(counter, _parse(t, "$a = (1 + 2) * 3;"))
+	if counter.depth != 0 {
+		t.Errorf("Expected balanced walk but depth is %d", counter.depth)
+	}
+	// Program, ExprStmt, AssignExpr, BinaryExpr, ParenExpr, BinaryExpr, NumberLit
+	if counter.max != 7 {
+		t.Errorf("Expected max depth 7 but got %d", counter.max)
+	}
+}
+
+func TestWalkComments(t *testing.T) {
+	program := _parse(t, "# first\n$a = 1; /* second */\n", tokenizer.WithTrivia())
+	var sb strings.Builder
+	if err := ast.Fprint(&sb, program); err != nil {
+		t.Fatalf("Print failed with err: %v", err)
+	}
+	expected := `Program string:2:1
+  ExprStmt string:2:1
+    AssignExpr = string:2:1
+      Variable $a string:2:1
+      NumberLit 1 string:2:6
+  Comment "# first" string:1:1
+  Comment "/* second */" string:2:9
+`
+	if sb.String() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, sb.String())
+	}
+	counter := &_depthCounter{}
+	ast.Walk(counter, program.Comments[0])
+	if counter.depth != 0 || counter.max != 1 {
+		t.Errorf("Expected single comment to be visited but got depth %d", counter.max)
+	}
+}
+
+func TestPositions(t *testing.T) {
+	program := _parse(t, "func f($a) {\n    = $a + foo(1, \"xy\");\n}\n")
+	decl := program.Stmts[0].(*ast.FuncDecl)
+	if !_at(decl.Pos(), 1, 1) || !_at(decl.End(), 3, 2) {
+		t.Errorf("Unexpected function span %v - %v", decl.Pos(), decl.End())
+	}
+	ret := decl.Body.Stmts[0].(*ast.ReturnStmt)
+	if !_at(ret.Pos(), 2, 5) || !_at(ret.End(), 2, 25) {
+		t.Errorf("Unexpected return span %v - %v", ret.Pos(), ret.End())
+	}
+	sum := ret.Result.(*ast.BinaryExpr)
+	if !_at(sum.Pos(), 2, 7) || !_at(sum.OpPos, 2, 10) || !_at(sum.End(), 2, 24) {
+		t.Errorf("Unexpected binary expression span %v - %v", sum.Pos(), sum.End())
+	}
+	call := sum.Y.(*ast.CallExpr)
+	str := call.Args[1].(*ast.StringLit)
+	if !_at(str.Pos(), 2, 19) || !_at(str.End(), 2, 23) {
+		t.Errorf("Unexpected string span %v - %v", str.Pos(), str.End())
+	}
+	if decl.Pos().SourceName != "string" {
+		t.Errorf("Unexpected source name %q", decl.Pos().SourceName)
+	}
+}
+
+func TestFprint(t *testing.T) {
+	program := _parse(t, "func f($a) {\n    = -$a + \"x\";\n}\n$n ++;")
+	var sb strings.Builder
+	if err := ast.Fprint(&sb, program); err != nil {
+		t.Fatalf("Print failed with err: %v", err)
+	}
+	expected := `Program string:1:1
+  FuncDecl string:1:1
+    Ident f string:1:6
+    Variable $a string:1:8
+    BlockStmt string:1:12
+      ReturnStmt = string:2:5
+        BinaryExpr + string:2:7
+          UnaryExpr - string:2:7
+            Variable $a string:2:8
+          StringLit "x" string:2:13
+  ExprStmt string:4:1
+    IncDecExpr postfix ++ string:4:1
+      Variable $n string:4:1
+`
+	if sb.String() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, sb.String())
+	}
+}
+
+func TestMultilineStringEnd(t *testing.T) {
+	program := _parse(t, "$s = `a\\b\n  c`;")
+	lit := program.Stmts[0].(*ast.ExprStmt).X.(*ast.AssignExpr).Rhs.(*ast.StringLit)
+	if lit.Value != "a\\b\n  c" || !_at(lit.Pos(), 1, 6) || !_at(lit.End(), 2, 5) {
+		t.Errorf("Unexpected raw string literal %q at %v-%v", lit.Value, lit.Pos(), lit.End())
+	}
+}
+
+func TestInterpolatedStringPositions(t *testing.T) {
+	program := _parse(t, "= \"a ${$b} c\";")
+	x := program.Stmts[0].(*ast.ReturnStmt).Result.(*ast.InterpolatedString)
+	if !_at(x.Pos(), 1, 3) || !_at(x.End(), 1, 14) {
+		t.Errorf("Unexpected interpolated string position %v-%v", x.Pos(), x.End())
+	}
+	var visited []string
+	ast.Inspect(x, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.StringLit:
+			visited = append(visited, n.Value)
+		case *ast.Variable:
+			visited = append(visited, "$"+n.Name)
+		}
+		return true
+	})
+	if strings.Join(visited, "|") != "a |$b| c" {
+		t.Errorf("Unexpected order of visited nodes %v", visited)
+	}
+}
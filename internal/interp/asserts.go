@@ -0,0 +1,64 @@
+package interp
+
+import (
+	"fmt"
+
+	"github.com/Allexy/fishes/internal/ast"
+	"github.com/Allexy/fishes/internal/value"
+)
+
+// Prefix of assertion messages, scripts rely on it
+const AssertionPrefix = "java.lang.AssertionError: "
+
+func registerAsserts(in *Interpreter) {
+	in.RegisterBuiltin("assertTrue", builtinAssertTrue)
+	in.RegisterBuiltin("assertFalse", builtinAssertFalse)
+	in.RegisterBuiltin("assertEquals", builtinAssertEquals)
+	in.RegisterBuiltin("assertNotEquals", builtinAssertNotEquals)
+}
+
+// assertTrue($v) passes if $v is cast to logic true
+func builtinAssertTrue(in *Interpreter, args []value.Value, pos ast.Position) (value.Value, error) {
+	if !value.ToLogic(arg(args, 0)) {
+		return nil, assertionFailed("Expected logic true value", pos)
+	}
+	return value.Logic(true), nil
+}
+
+// assertFalse($v) passes if $v is cast to logic false
+func builtinAssertFalse(in *Interpreter, args []value.Value, pos ast.Position) (value.Value, error) {
+	if value.ToLogic(arg(args, 0)) {
+		return nil, assertionFailed("Expected logic false value", pos)
+	}
+	return value.Logic(true), nil
+}
+
+// assertEquals($v1, $v2) passes if $v1 == $v2
+func builtinAssertEquals(in *Interpreter, args []value.Value, pos ast.Position) (value.Value, error) {
+	v1, v2 := arg(args, 0), arg(args, 1)
+	if !value.Equals(v1, v2) {
+		return nil, assertionFailed(fmt.Sprintf("Expected first argument equals to second: v1 = %s v2 = %s", value.ToString(v1), value.ToString(v2)), pos)
+	}
+	return value.Logic(true), nil
+}
+
+// assertNotEquals($v1, $v2) passes if $v1 != $v2
+func builtinAssertNotEquals(in *Interpreter, args []value.Value, pos ast.Position) (value.Value, error) {
+	v1, v2 := arg(args, 0), arg(args, 1)
+	if value.Equals(v1, v2) {
+		return nil, assertionFailed(fmt.Sprintf("Expected first argument not equals to second: v1 = %s v2 = %s", value.ToString(v1), value.ToString(v2)), pos)
+	}
+	return value.Logic(true), nil
+}
+
+func assertionFailed(message string, pos ast.Position) *Exception {
+	return NewException(CodeAssertion, AssertionPrefix+message, pos)
+}
+
+// Returns argument by index, omitted arguments are null
+func arg(args []value.Value, i int) value.Value {
+	if i < len(args) {
+		return args[i]
+	}
+	return value.NullValue
+}
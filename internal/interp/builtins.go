@@ -0,0 +1,58 @@
+package interp
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Allexy/fishes/internal/ast"
+	"github.com/Allexy/fishes/internal/value"
+)
+
+// Prefix of test function names
+const TestPrefix = "test"
+
+func registerBuiltins(in *Interpreter) {
+	in.RegisterBuiltin("print", builtinPrint)
+	in.RegisterBuiltin("nextTest", builtinNextTest)
+	in.RegisterBuiltin("execTest", builtinExecTest)
+	registerAsserts(in)
+}
+
+// print(args...) writes concatenated arguments followed by new line
+func builtinPrint(in *Interpreter, args []value.Value, pos ast.Position) (value.Value, error) {
+	var sb strings.Builder
+	for _, a := range args {
+		sb.WriteString(string(value.ToString(a)))
+	}
+	sb.WriteByte('\n')
+	if _, err := io.WriteString(in.out, sb.String()); err != nil {
+		return nil, err
+	}
+	return value.NullValue, nil
+}
+
+// nextTest() returns name of the next declared test function or false when there are no more tests
+func builtinNextTest(in *Interpreter, args []value.Value, pos ast.Position) (value.Value, error) {
+	for in.next < len(in.order) {
+		name := in.order[in.next]
+		in.next++
+		if strings.HasPrefix(name, TestPrefix) {
+			return value.String(name), nil
+		}
+	}
+	return value.Logic(false), nil
+}
+
+// execTest(name) calls test function without arguments and returns its result
+func builtinExecTest(in *Interpreter, args []value.Value, pos ast.Position) (value.Value, error) {
+	if len(args) == 0 {
+		return nil, NewException(CodeArgument, "execTest expects test name", pos)
+	}
+	name := string(value.ToString(arg(args, 0)))
+	fn, ok := in.funcs[name]
+	if !ok {
+		return nil, NewException(CodeUndefined, fmt.Sprintf("Undefined test function %s", name), pos)
+	}
+	return in.call(fn, nil, pos)
+}
@@ -0,0 +1,145 @@
+package interp
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/Allexy/fishes/internal/ast"
+	"github.com/Allexy/fishes/internal/value"
+)
+
+// Exception codes, values are part of the language and must never change
+const (
+	CodeRuntime        = 1 // generic runtime error
+	CodeThrown         = 2 // raised by throw statement
+	CodeDivisionByZero = 3
+	CodeAssertion      = 4 // failed assertion
+	CodeUndefined      = 5 // undefined variable or function
+	CodeNotCallable    = 6 // call of value which is not a function
+	CodeArgument       = 7 // invalid argument of built-in function
+	CodeStackOverflow  = 8 // maximum call depth exceeded
+	CodeIO             = 9 // file system or input/output error of host function
+	CodeHost           = 10
+)
+
+var codeNames = map[int]string{
+	CodeRuntime:        "RuntimeError",
+	CodeThrown:         "Exception",
+	CodeDivisionByZero: "DivisionByZero",
+	CodeAssertion:      "AssertionError",
+	CodeUndefined:      "UndefinedError",
+	CodeNotCallable:    "NotCallableError",
+	CodeArgument:       "ArgumentError",
+	CodeStackOverflow:  "StackOverflow",
+	CodeIO:             "IOError",
+	CodeHost:           "HostError",
+}
+
+// Returns name of exception code
+func CodeName(code int) string {
+	if name, ok := codeNames[code]; ok {
+		return name
+	}
+	return fmt.Sprintf("Code%d", code)
+}
+
+// Host errors implementing this interface keep their own exception code
+type CodedError interface {
+	error
+	ExceptionCode() int
+}
+
+// Single entry of script stack trace
+type Frame struct {
+	Func string // name of function, empty for lambdas
+	Pos  ast.Position
+}
+
+func (f Frame) String() string {
+	name := f.Func
+	if name == "" {
+		name = "<lambda>"
+	}
+	return fmt.Sprintf("at %s (%v)", name, f.Pos)
+}
+
+// Limit of frames kept in stack trace
+const maxStackFrames = 100
+
+// Script level exception, can be caught by try/catch statement
+type Exception struct {
+	Code    int
+	Message string
+	Pos     ast.Position // where exception was raised
+	Stack   []Frame      // innermost frame first, filled while exception unwinds calls
+	cause   error
+	current ast.Position // position in the frame being unwound
+}
+
+func NewException(code int, message string, pos ast.Position) *Exception {
+	return &Exception{Code: code, Message: message, Pos: pos, current: pos}
+}
+
+// Maps error returned by host function to exception
+func FromError(err error, pos ast.Position) *Exception {
+	var e *Exception
+	if errors.As(err, &e) {
+		return e
+	}
+	var coded CodedError
+	var pathErr *fs.PathError
+	var ex *Exception
+	switch {
+	case errors.Is(err, value.ErrDivisionByZero):
+		ex = NewException(CodeDivisionByZero, "Division by zero", pos)
+	case errors.As(err, &coded):
+		ex = NewException(coded.ExceptionCode(), err.Error(), pos)
+	case errors.As(err, &pathErr), errors.Is(err, fs.ErrNotExist), errors.Is(err, fs.ErrPermission):
+		ex = NewException(CodeIO, err.Error(), pos)
+	default:
+		ex = NewException(CodeHost, err.Error(), pos)
+	}
+	ex.cause = err
+	return ex
+}
+
+func (e *Exception) Error() string {
+	return fmt.Sprintf("Error in file %s: %s (code %d)\nAt line %d; col: %d", e.Pos.SourceName, e.Message, e.Code, e.Pos.Line, e.Pos.Col)
+}
+
+func (e *Exception) Cause() error {
+	return e.cause
+}
+
+func (e *Exception) Unwrap() error {
+	return e.cause
+}
+
+// Renders exception with its stack trace
+func (e *Exception) StackTrace() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s (code %d): %s", CodeName(e.Code), e.Code, e.Message)
+	for _, f := range e.Stack {
+		sb.WriteString("\n\t")
+		sb.WriteString(f.String())
+	}
+	return sb.String()
+}
+
+// Records frame of function which is left by exception, callPos becomes position in the caller
+func (e *Exception) unwind(fn string, callPos ast.Position) {
+	if len(e.Stack) < maxStackFrames {
+		e.Stack = append(e.Stack, Frame{Func: fn, Pos: e.current})
+	}
+	e.current = callPos
+}
+
+// Records outermost frame
+func (e *Exception) unwindMain() {
+	if e.current.IsValid() && len(e.Stack) < maxStackFrames {
+		e.Stack = append(e.Stack, Frame{Func: "main", Pos: e.current})
+		e.current = ast.Position{}
+	}
+}
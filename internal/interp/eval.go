@@ -0,0 +1,188 @@
+package interp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Allexy/fishes/internal/ast"
+	"github.com/Allexy/fishes/internal/lang"
+	"github.com/Allexy/fishes/internal/value"
+)
+
+func (in *Interpreter) eval(x ast.Expr, s *scope) (value.Value, error) {
+	switch x := x.(type) {
+	case *ast.NumberLit:
+		return value.NewRational(x.Value), nil
+	case *ast.StringLit:
+		return value.String(x.Value), nil
+	case *ast.InterpolatedString:
+		return in.evalInterpolated(x, s)
+	case *ast.LogicLit:
+		return value.Logic(x.Value), nil
+	case *ast.NullLit:
+		return value.NullValue, nil
+	case *ast.Ident:
+		return nil, NewException(CodeUndefined, fmt.Sprintf("Unexpected identifier %s, use @%s to refer to function", x.Name, x.Name), x.Pos())
+	case *ast.Variable:
+		v, ok := s.lookup(x.Name)
+		if !ok {
+			return nil, NewException(CodeUndefined, fmt.Sprintf("Undefined variable $%s", x.Name), x.Pos())
+		}
+		return v, nil
+	case *ast.FuncRef:
+		fn, err := in.lookupFunc(x.Name, x.Pos())
+		if err != nil {
+			return nil, err
+		}
+		return fn, nil
+	case *ast.FuncLit:
+		return &Function{params: x.Params, body: x.Body, closure: s}, nil
+	case *ast.ParenExpr:
+		return in.eval(x.X, s)
+	case *ast.CallExpr:
+		return in.evalCall(x, s)
+	case *ast.UnaryExpr:
+		v, err := in.eval(x.X, s)
+		if err != nil {
+			return nil, err
+		}
+		res, err := value.Unary(x.Op, v)
+		if err != nil {
+			return nil, NewException(CodeRuntime, err.Error(), x.Pos())
+		}
+		return res, nil
+	case *ast.IncDecExpr:
+		return in.evalIncDec(x, s)
+	case *ast.BinaryExpr:
+		return in.evalBinary(x, s)
+	case *ast.AssignExpr:
+		return in.evalAssign(x, s)
+	}
+	panic(fmt.Sprintf("interp: unexpected expression type %T", x))
+}
+
+func (in *Interpreter) evalCall(x *ast.CallExpr, s *scope) (value.Value, error) {
+	var fn *Function
+	if id, ok := x.Fun.(*ast.Ident); ok {
+		f, err := in.lookupFunc(id.Name, id.Pos())
+		if err != nil {
+			return nil, err
+		}
+		fn = f
+	} else {
+		v, err := in.eval(x.Fun, s)
+		if err != nil {
+			return nil, err
+		}
+		f, ok := v.(*Function)
+		if !ok {
+			return nil, NewException(CodeNotCallable, fmt.Sprintf("Value %q is not a function", v.String()), x.Fun.Pos())
+		}
+		fn = f
+	}
+	args := make([]value.Value, len(x.Args))
+	for i, a := range x.Args {
+		v, err := in.eval(a, s)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	return in.call(fn, args, x.Pos())
+}
+
+// Concatenates string parts and values of embedded expressions cast to strings
+func (in *Interpreter) evalInterpolated(x *ast.InterpolatedString, s *scope) (value.Value, error) {
+	var sb strings.Builder
+	for i, part := range x.Parts {
+		sb.WriteString(part.Value)
+		if i < len(x.Exprs) {
+			v, err := in.eval(x.Exprs[i], s)
+			if err != nil {
+				return nil, err
+			}
+			sb.WriteString(string(value.ToString(v)))
+		}
+	}
+	return value.String(sb.String()), nil
+}
+
+func (in *Interpreter) evalIncDec(x *ast.IncDecExpr, s *scope) (value.Value, error) {
+	old, err := in.eval(x.X, s)
+	if err != nil {
+		return nil, err
+	}
+	n := value.ToNumber(old)
+	delta := value.NewNumber(1)
+	if x.Op == lang.OpDecrement {
+		delta = value.NewNumber(-1)
+	}
+	updated := value.Add(n, delta)
+	s.assign(x.X.Name, updated)
+	if x.Postfix {
+		return n, nil
+	}
+	return updated, nil
+}
+
+func (in *Interpreter) evalAssign(x *ast.AssignExpr, s *scope) (value.Value, error) {
+	v, err := in.eval(x.Rhs, s)
+	if err != nil {
+		return nil, err
+	}
+	if x.Op != lang.OpAssign {
+		old, err := in.eval(x.Lhs, s)
+		if err != nil {
+			return nil, err
+		}
+		// "+=" => "+"
+		op := x.Op[:len(x.Op)-1]
+		if v, err = binary(op, old, v, x.Pos()); err != nil {
+			return nil, err
+		}
+	}
+	s.assign(x.Lhs.Name, v)
+	return v, nil
+}
+
+func (in *Interpreter) evalBinary(x *ast.BinaryExpr, s *scope) (value.Value, error) {
+	left, err := in.eval(x.X, s)
+	if err != nil {
+		return nil, err
+	}
+	// logical operators are short-circuit
+	switch x.Op {
+	case lang.OpAnd:
+		if !value.ToLogic(left) {
+			return value.Logic(false), nil
+		}
+		right, err := in.eval(x.Y, s)
+		if err != nil {
+			return nil, err
+		}
+		return value.ToLogic(right), nil
+	case lang.OpOr:
+		if value.ToLogic(left) {
+			return value.Logic(true), nil
+		}
+		right, err := in.eval(x.Y, s)
+		if err != nil {
+			return nil, err
+		}
+		return value.ToLogic(right), nil
+	}
+	right, err := in.eval(x.Y, s)
+	if err != nil {
+		return nil, err
+	}
+	return binary(x.Op, left, right, x.OpPos)
+}
+
+// Evaluates binary operator and maps its errors to exceptions
+func binary(op string, left, right value.Value, pos ast.Position) (value.Value, error) {
+	res, err := value.Binary(op, left, right)
+	if err != nil {
+		return nil, FromError(err, pos)
+	}
+	return res, nil
+}
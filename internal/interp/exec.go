@@ -0,0 +1,154 @@
+package interp
+
+import (
+	"fmt"
+
+	"github.com/Allexy/fishes/internal/ast"
+	"github.com/Allexy/fishes/internal/value"
+)
+
+// Executes statement, returned is true if return statement was executed
+func (in *Interpreter) exec(stmt ast.Stmt, s *scope) (res value.Value, returned bool, err error) {
+	switch stmt := stmt.(type) {
+	case *ast.BlockStmt:
+		return in.execBlock(stmt, s)
+	case *ast.ExprStmt:
+		_, err := in.eval(stmt.X, s)
+		return nil, false, err
+	case *ast.ReturnStmt:
+		if stmt.Result == nil {
+			return value.NullValue, true, nil
+		}
+		res, err := in.eval(stmt.Result, s)
+		if err != nil {
+			return nil, false, err
+		}
+		return res, true, nil
+	case *ast.ThrowStmt:
+		v, err := in.eval(stmt.Value, s)
+		if err != nil {
+			return nil, false, err
+		}
+		return nil, false, NewException(CodeThrown, string(value.ToString(v)), stmt.Pos())
+	case *ast.IfStmt:
+		cond, err := in.eval(stmt.Cond, s)
+		if err != nil {
+			return nil, false, err
+		}
+		if value.ToLogic(cond) {
+			return in.execBlock(stmt.Body, s)
+		}
+		if stmt.Else != nil {
+			return in.exec(stmt.Else, s)
+		}
+		return nil, false, nil
+	case *ast.WhileStmt:
+		for {
+			cond, err := in.eval(stmt.Cond, s)
+			if err != nil {
+				return nil, false, err
+			}
+			if !value.ToLogic(cond) {
+				return nil, false, nil
+			}
+			if res, returned, err := in.execBlock(stmt.Body, s); err != nil || returned {
+				return res, returned, err
+			}
+		}
+	case *ast.DoWhileStmt:
+		for {
+			if res, returned, err := in.execBlock(stmt.Body, s); err != nil || returned {
+				return res, returned, err
+			}
+			cond, err := in.eval(stmt.Cond, s)
+			if err != nil {
+				return nil, false, err
+			}
+			if !value.ToLogic(cond) {
+				return nil, false, nil
+			}
+		}
+	case *ast.ForStmt:
+		return in.execFor(stmt, s)
+	case *ast.SwitchStmt:
+		return in.execSwitch(stmt, s)
+	case *ast.TryStmt:
+		return in.execTry(stmt, s)
+	case *ast.FuncDecl:
+		return nil, false, NewException(CodeRuntime, "Function declaration is allowed only at top level", stmt.Pos())
+	}
+	panic(fmt.Sprintf("interp: unexpected statement type %T", stmt))
+}
+
+// Blocks do not introduce new scope, variables are visible until function ends
+func (in *Interpreter) execBlock(block *ast.BlockStmt, s *scope) (value.Value, bool, error) {
+	for _, stmt := range block.Stmts {
+		if res, returned, err := in.exec(stmt, s); err != nil || returned {
+			return res, returned, err
+		}
+	}
+	return nil, false, nil
+}
+
+func (in *Interpreter) execFor(stmt *ast.ForStmt, s *scope) (value.Value, bool, error) {
+	if stmt.Init != nil {
+		if _, err := in.eval(stmt.Init, s); err != nil {
+			return nil, false, err
+		}
+	}
+	for {
+		if stmt.Cond != nil {
+			cond, err := in.eval(stmt.Cond, s)
+			if err != nil {
+				return nil, false, err
+			}
+			if !value.ToLogic(cond) {
+				return nil, false, nil
+			}
+		}
+		if res, returned, err := in.execBlock(stmt.Body, s); err != nil || returned {
+			return res, returned, err
+		}
+		if stmt.Post != nil {
+			if _, err := in.eval(stmt.Post, s); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+}
+
+// Executes body of the first case which value equals to tag, there is no fall through
+func (in *Interpreter) execSwitch(stmt *ast.SwitchStmt, s *scope) (value.Value, bool, error) {
+	tag, err := in.eval(stmt.Tag, s)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, clause := range stmt.Cases {
+		v, err := in.eval(clause.Value, s)
+		if err != nil {
+			return nil, false, err
+		}
+		if value.Equals(tag, v) {
+			return in.execBlock(clause.Body, s)
+		}
+	}
+	return nil, false, nil
+}
+
+func (in *Interpreter) execTry(stmt *ast.TryStmt, s *scope) (value.Value, bool, error) {
+	res, returned, err := in.execBlock(stmt.Body, s)
+	if err == nil {
+		return res, returned, nil
+	}
+	e, ok := err.(*Exception)
+	if !ok {
+		return nil, false, err
+	}
+	if stmt.Code != nil {
+		s.assign(stmt.Code.Name, value.NewNumber(int64(e.Code)))
+	}
+	if stmt.Msg != nil {
+		s.assign(stmt.Msg.Name, value.String(e.Message))
+	}
+	return in.execBlock(stmt.Catch, s)
+}
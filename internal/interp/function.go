@@ -0,0 +1,63 @@
+package interp
+
+import (
+	"github.com/Allexy/fishes/internal/ast"
+	"github.com/Allexy/fishes/internal/value"
+)
+
+// Function implemented in Go
+type NativeFunc func(in *Interpreter, args []value.Value, pos ast.Position) (value.Value, error)
+
+// Function value: declared function, lambda with its closure or built-in
+type Function struct {
+	Name    string
+	params  []*ast.Variable
+	body    *ast.BlockStmt
+	closure *scope // nil for declared functions, they do not see outer variables
+	native  NativeFunc
+}
+
+func (*Function) Type() value.Type { return value.TypeFunction }
+
+func (f *Function) String() string {
+	if f.Name == "" {
+		return "func"
+	}
+	return "func " + f.Name
+}
+
+// Variables scope
+type scope struct {
+	vars   map[string]value.Value
+	parent *scope
+}
+
+func newScope(parent *scope) *scope {
+	return &scope{vars: make(map[string]value.Value, 8), parent: parent}
+}
+
+// Finds variable in scope or its parents
+func (s *scope) lookup(name string) (value.Value, bool) {
+	for c := s; c != nil; c = c.parent {
+		if v, ok := c.vars[name]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// Sets variable where it is defined, or defines new one in current scope
+func (s *scope) assign(name string, v value.Value) {
+	for c := s; c != nil; c = c.parent {
+		if _, ok := c.vars[name]; ok {
+			c.vars[name] = v
+			return
+		}
+	}
+	s.vars[name] = v
+}
+
+// Defines variable in current scope
+func (s *scope) define(name string, v value.Value) {
+	s.vars[name] = v
+}
@@ -0,0 +1,167 @@
+package interp
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Allexy/fishes/internal/ast"
+	"github.com/Allexy/fishes/internal/value"
+)
+
+// Limit of nested calls
+const maxCallDepth = 10000
+
+type Interpreter struct {
+	out      io.Writer
+	builtins map[string]*Function
+	funcs    map[string]*Function
+	order    []string // names of declared functions in order of declaration
+	globals  *scope
+	depth    int
+	next     int // index of next test function returned by nextTest()
+}
+
+func NewInterpreter(out io.Writer) *Interpreter {
+	in := &Interpreter{
+		out:      out,
+		builtins: make(map[string]*Function, 16),
+		funcs:    make(map[string]*Function, 64),
+		order:    make([]string, 0, 64),
+		globals:  newScope(nil),
+	}
+	registerBuiltins(in)
+	return in
+}
+
+// Registers function implemented in Go
+func (in *Interpreter) RegisterBuiltin(name string, fn NativeFunc) {
+	in.builtins[name] = &Function{Name: name, native: fn}
+}
+
+// Declares program's functions and applies decorators, top level statements are not executed
+func (in *Interpreter) Load(program *ast.Program) error {
+	decls := program.Funcs()
+	for _, decl := range decls {
+		if _, ok := in.funcs[decl.Name.Name]; ok {
+			return NewException(CodeRuntime, fmt.Sprintf("Function %s is already declared", decl.Name.Name), decl.Name.Pos())
+		}
+		in.funcs[decl.Name.Name] = &Function{Name: decl.Name.Name, params: decl.Params, body: decl.Body}
+		in.order = append(in.order, decl.Name.Name)
+	}
+	for _, decl := range decls {
+		// closest to declaration decorator is applied first
+		for i := len(decl.Decorators) - 1; i >= 0; i-- {
+			d := decl.Decorators[i]
+			decorator, err := in.lookupFunc(d.Name, d.Pos())
+			if err != nil {
+				return err
+			}
+			res, err := in.call(decorator, []value.Value{in.funcs[decl.Name.Name]}, d.Pos())
+			if err != nil {
+				return err
+			}
+			fn, ok := res.(*Function)
+			if !ok {
+				return NewException(CodeRuntime, fmt.Sprintf("Decorator %s must return function", d.Name), d.Pos())
+			}
+			in.funcs[decl.Name.Name] = fn
+		}
+	}
+	return nil
+}
+
+// Loads program and executes its top level statements, returns value of top level return statement
+func (in *Interpreter) Run(program *ast.Program) (value.Value, error) {
+	if err := in.Load(program); err != nil {
+		return nil, unwindMain(err)
+	}
+	for _, stmt := range program.Stmts {
+		if _, ok := stmt.(*ast.FuncDecl); ok {
+			continue
+		}
+		res, returned, err := in.exec(stmt, in.globals)
+		if err != nil {
+			return nil, unwindMain(err)
+		}
+		if returned {
+			return res, nil
+		}
+	}
+	return value.NullValue, nil
+}
+
+// Calls declared or built-in function by name
+func (in *Interpreter) Call(name string, args ...value.Value) (value.Value, error) {
+	fn, err := in.lookupFunc(name, ast.Position{})
+	if err != nil {
+		return nil, err
+	}
+	res, err := in.call(fn, args, ast.Position{})
+	return res, unwindMain(err)
+}
+
+// Returns names of declared functions in order of declaration
+func (in *Interpreter) Funcs() []string {
+	return in.order
+}
+
+func (in *Interpreter) lookupFunc(name string, pos ast.Position) (*Function, error) {
+	if fn, ok := in.funcs[name]; ok {
+		return fn, nil
+	}
+	if fn, ok := in.builtins[name]; ok {
+		return fn, nil
+	}
+	return nil, NewException(CodeUndefined, fmt.Sprintf("Undefined function %s", name), pos)
+}
+
+func (in *Interpreter) call(fn *Function, args []value.Value, pos ast.Position) (value.Value, error) {
+	if in.depth >= maxCallDepth {
+		return nil, NewException(CodeStackOverflow, "Maximum call depth exceeded", pos)
+	}
+	in.depth++
+	defer func() { in.depth-- }()
+	if fn.native != nil {
+		return in.callNative(fn, args, pos)
+	}
+	local := newScope(fn.closure)
+	for i, param := range fn.params {
+		var arg value.Value = value.NullValue
+		if i < len(args) {
+			arg = args[i]
+		}
+		local.define(param.Name, arg)
+	}
+	res, returned, err := in.execBlock(fn.body, local)
+	if err != nil {
+		if e, ok := err.(*Exception); ok {
+			e.unwind(fn.Name, pos)
+		}
+		return nil, err
+	}
+	if !returned {
+		return value.NullValue, nil
+	}
+	return res, nil
+}
+
+// Calls built-in function, its errors and panics are mapped to exceptions
+func (in *Interpreter) callNative(fn *Function, args []value.Value, pos ast.Position) (res value.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			res, err = nil, NewException(CodeHost, fmt.Sprintf("Function %s failed: %v", fn.Name, r), pos)
+		}
+	}()
+	if res, err = fn.native(in, args, pos); err != nil {
+		return nil, FromError(err, pos)
+	}
+	return res, nil
+}
+
+// Completes stack trace of exception which reached the outermost level
+func unwindMain(err error) error {
+	if e, ok := err.(*Exception); ok {
+		e.unwindMain()
+	}
+	return err
+}
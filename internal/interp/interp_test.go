@@ -0,0 +1,299 @@
+package interp
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Allexy/fishes/internal/ast"
+	"github.com/Allexy/fishes/internal/parser"
+	"github.com/Allexy/fishes/internal/tokenizer"
+	"github.com/Allexy/fishes/internal/value"
+)
+
+// Runs source given as string, returns result and printed output
+func _run(t *testing.T, s string) (value.Value, string, error) {
+	return _runWith(t, s, nil)
+}
+
+// Runs source with additional built-in functions
+func _runWith(t *testing.T, s string, builtins map[string]NativeFunc) (value.Value, string, error) {
+	tw, err := tokenizer.NewTokenizer(strings.NewReader(s), "string").Tokenize()
+	if err != nil {
+		t.Fatalf("Tokenization failed with err: %v", err)
+	}
+	program, err := parser.NewParser(tw).Parse()
+	if err != nil {
+		t.Fatalf("Parsing failed with err: %v", err)
+	}
+	var out strings.Builder
+	in := NewInterpreter(&out)
+	for name, fn := range builtins {
+		in.RegisterBuiltin(name, fn)
+	}
+	res, err := in.Run(program)
+	return res, out.String(), err
+}
+
+// Runs source and compares its result rendered as string
+func _expect(t *testing.T, s string, expected string) {
+	res, _, err := _run(t, s)
+	if err != nil {
+		t.Errorf("Execution of %q failed with err: %v", s, err)
+		return
+	}
+	if res.String() != expected {
+		t.Errorf("Expected %q for %q but got %q", expected, s, res.String())
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	_expect(t, "= 2 + 2 * 2;", "6")
+	_expect(t, "= -2 * (2 + 2) * -2;", "16")
+	_expect(t, "= 2.0 / (2.0 * 2.0) * 1.0;", "0.5")
+	_expect(t, "= 1 / 3;", "0.3333333333333333")
+	_expect(t, "= 7 % 3;", "1")
+	_expect(t, "= -7 % 3;", "-1")
+	_expect(t, "= 0.1 * 0.1 * 0.1 == 0.001;", "true")
+	_expect(t, "= \"xyz\" + 1;", "xyz1")
+	_expect(t, "= 1 + null;", "NaN")
+	_expect(t, "= 0xFF + 0b1 + 0o10 + 1_000 + 2.5e3 + 1e-1;", "3764.1")
+}
+
+func TestDivisionByZero(t *testing.T) {
+	_, _, err := _run(t, "= 1 / 0;")
+	e, ok := err.(*Exception)
+	if !ok {
+		t.Fatalf("Expected exception but got %v", err)
+	}
+	if e.Code != CodeDivisionByZero || e.Message != "Division by zero" {
+		t.Errorf("Unexpected exception %v", e)
+	}
+}
+
+func TestComparison(t *testing.T) {
+	_expect(t, "= \"StRiNg\" == \"sTrInG\";", "true")
+	_expect(t, "= \"это текст UTF8\" == \"ЭТО ТЕКСТ utf8\";", "true")
+	_expect(t, "= true == \"not empty\";", "true")
+	_expect(t, "= \"true\" == true;", "true")
+	_expect(t, "= false == null;", "false")
+	_expect(t, "= \"\" == null;", "true")
+	_expect(t, "= null == null;", "true")
+	_expect(t, "= !1 > 2 && !2 < 1;", "true")
+	_expect(t, "= !1 < 2;", "false")
+}
+
+// Null is NaN in numeric context, empty in string context and false in logic context (test_11, test_15)
+func TestNull(t *testing.T) {
+	_expect(t, "= null;", "")
+	_expect(t, "= null + 1;", "NaN")
+	_expect(t, "= -null;", "NaN")
+	_expect(t, "= null * 0 == 0;", "false")
+	_expect(t, "= \"a\" + null + \"b\";", "ab")
+	_expect(t, "= \"[${null}]\";", "[]")
+	_expect(t, "= !null;", "true")
+	_expect(t, "= null || 0;", "false")
+	_expect(t, "= null > -1 || null < 1;", "false")
+	_expect(t, "func f($a) { = $a == null; } = f();", "true")
+	res, _, err := _run(t, "= null;")
+	if err != nil || res != value.NullValue {
+		t.Errorf("Expected null value but got %#v (err: %v)", res, err)
+	}
+}
+
+func TestVariables(t *testing.T) {
+	_expect(t, "$a = 2; $b = ++ $a; = $a + $b;", "6")
+	_expect(t, "$a = 2; $b = $a++ - 1; = $a * 10 + $b;", "31")
+	_expect(t, "$a = 2; = $a-- + 2;", "4")
+	_expect(t, "$a = 2; $b = $a --; = $b * 10 + $a;", "21")
+	_expect(t, "$res = 1; $res += 200; = $res;", "201")
+	_, _, err := _run(t, "= $undefined;")
+	if e, ok := err.(*Exception); !ok || e.Code != CodeUndefined {
+		t.Errorf("Expected runtime exception but got %v", err)
+	}
+}
+
+func TestControlFlow(t *testing.T) {
+	_expect(t, "$n = 0; while($n < 10) { $n ++; } = $n;", "10")
+	_expect(t, "$n = 10; do { $n ++; } while($n < 10); = $n;", "11")
+	_expect(t, "for($a = 5; $a > -1; $a --) { } = $a;", "-1")
+	_expect(t, "if(false) { = 1; } else if(true) { = 2; } = 3;", "2")
+	_expect(t, "$a = 1; switch(2) { case($a * 3 + 1) { = 1; } case(($a + 1) * 2 - 2) { = 2; } } = 3;", "2")
+	_expect(t, "$a = \"xyz\"; switch($a) { case(\"XYZ\") { } case($a) { = 1; } } = 2;", "2")
+}
+
+func TestTryCatch(t *testing.T) {
+	_expect(t, "try { throw(\"oops\"); } catch($code, $msg) { = $msg + $code; }", "oops2")
+	_expect(t, "func zero() { = 0; } try { $n = 1 / zero(); } catch($code, $msg) { = $code; }", "3")
+	_expect(t, "try { = 1; } catch($code, $msg) { = 2; }", "1")
+}
+
+func TestFunctions(t *testing.T) {
+	_expect(t, "func sum($a, $b) { = $a + $b; } = 1 + sum(1 + 1, 2 + 1) + 1;", "7")
+	_expect(t, "func getEmpty($a, $b) { = $b; } = getEmpty(1,) == null;", "true")
+	_expect(t, "func noReturn() { } = noReturn() == null;", "true")
+	_expect(t, "$sum = func($a, $b) { = $a + $b; }; = $sum(10, 5);", "15")
+	_expect(t, "func mk($n) { = func($a) { = $n + $a; }; } $f = mk(10); = $f(5);", "15")
+	_expect(t, "func mk($n) { = func($a) { = $n + $a; }; } $ref = @mk; = $ref(20)(1);", "21")
+	_expect(t, "$g = 1; func f() { = $g; } try { f(); } catch($c, $m) { = $m; }", "Undefined variable $g")
+}
+
+func TestDecorators(t *testing.T) {
+	source := `
+func plus100($func) { = func($a, $b) { = $func($a, $b) + 100; }; }
+func times2($func) { = func($a, $b) { = $func($a, $b) * 2; }; }
+@plus100
+@times2
+func sum($a, $b) { = $a + $b; }
+= sum(1, 2);`
+	_expect(t, source, "106")
+}
+
+func TestPrint(t *testing.T) {
+	_, out, err := _run(t, "print(\"a = \", 1.5, \" \", true); print();")
+	if err != nil {
+		t.Fatalf("Execution failed with err: %v", err)
+	}
+	if out != "a = 1.5 true\n\n" {
+		t.Errorf("Unexpected output %q", out)
+	}
+}
+
+func TestTestDriver(t *testing.T) {
+	source := `
+func testA() { = true; }
+func helper() { = false; }
+func testB() { = 1; }
+$names = "";
+while(($test = nextTest()) != false) {
+    if(execTest($test)) {
+        $names = $names + $test + ";";
+    }
+}
+= $names;`
+	_expect(t, source, "testA;testB;")
+}
+
+func TestCallDepth(t *testing.T) {
+	_, _, err := _run(t, "func f() { = f(); } = f();")
+	if e, ok := err.(*Exception); !ok || e.Code != CodeStackOverflow {
+		t.Errorf("Expected runtime exception but got %v", err)
+	}
+}
+
+type _codedError struct{}
+
+func (_codedError) Error() string      { return "coded" }
+func (_codedError) ExceptionCode() int { return 42 }
+
+func TestHostErrors(t *testing.T) {
+	builtins := map[string]NativeFunc{
+		"fail": func(in *Interpreter, args []value.Value, pos ast.Position) (value.Value, error) {
+			switch value.ToString(args[0]) {
+			case "io":
+				return nil, &fs.PathError{Op: "open", Path: "x.fs", Err: fs.ErrNotExist}
+			case "coded":
+				return nil, fmt.Errorf("wrapped: %w", _codedError{})
+			case "panic":
+				panic("boom")
+			}
+			return nil, errors.New("plain")
+		},
+	}
+	cases := map[string]string{
+		"io":    "9 open x.fs: file does not exist",
+		"coded": "42 wrapped: coded",
+		"panic": "10 Function fail failed: boom",
+		"plain": "10 plain",
+	}
+	for kind, expected := range cases {
+		source := fmt.Sprintf("try { fail(%q); } catch($code, $msg) { = \"\" + $code + \" \" + $msg; }", kind)
+		res, _, err := _runWith(t, source, builtins)
+		if err != nil {
+			t.Errorf("Execution failed with err: %v", err)
+			continue
+		}
+		if res.String() != expected {
+			t.Errorf("Expected %q but got %q", expected, res.String())
+		}
+	}
+	_, _, err := _runWith(t, "fail(\"io\");", builtins)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected exception to wrap host error but got %v", err)
+	}
+}
+
+func TestStackTrace(t *testing.T) {
+	source := "func zero() {\n    = 1 / 0;\n}\nfunc f() {\n    = zero();\n}\n$x = f();\n"
+	_, _, err := _run(t, source)
+	e, ok := err.(*Exception)
+	if !ok {
+		t.Fatalf("Expected exception but got %v", err)
+	}
+	expected := "DivisionByZero (code 3): Division by zero\n" +
+		"\tat zero (string:2:9)\n" +
+		"\tat f (string:5:7)\n" +
+		"\tat main (string:7:6)"
+	if e.StackTrace() != expected {
+		t.Errorf("Unexpected stack trace:\n%s", e.StackTrace())
+	}
+}
+
+func TestCodeNames(t *testing.T) {
+	if CodeName(CodeAssertion) != "AssertionError" || CodeName(99) != "Code99" {
+		t.Error("Unexpected code names")
+	}
+}
+
+func TestAssertions(t *testing.T) {
+	cases := map[string]string{
+		"assertTrue(false);":               "java.lang.AssertionError: Expected logic true value",
+		"assertFalse(1);":                  "java.lang.AssertionError: Expected logic false value",
+		"assertEquals(1, 2);":              "java.lang.AssertionError: Expected first argument equals to second: v1 = 1 v2 = 2",
+		"assertEquals(1.123456, 1.12345);": "java.lang.AssertionError: Expected first argument equals to second: v1 = 1.123456 v2 = 1.12345",
+		"assertEquals(\"a\");":             "java.lang.AssertionError: Expected first argument equals to second: v1 = a v2 = ",
+		"assertNotEquals(true, \"1\");":    "java.lang.AssertionError: Expected first argument not equals to second: v1 = true v2 = 1",
+	}
+	for source, expected := range cases {
+		_, _, err := _run(t, source)
+		e, ok := err.(*Exception)
+		if !ok {
+			t.Errorf("Expected exception for %q but got %v", source, err)
+			continue
+		}
+		if e.Code != CodeAssertion || e.Message != expected {
+			t.Errorf("Expected %q for %q but got %q (code %d)", expected, source, e.Message, e.Code)
+		}
+	}
+	_expect(t, "= assertEquals(\"StRiNg\", \"sTrInG\") && assertTrue(true) && assertFalse(\"\") && assertNotEquals(false, null);", "true")
+}
+
+func TestSelfTest(t *testing.T) {
+	source, err := os.ReadFile("../../self_test.fs")
+	if err != nil {
+		t.Fatalf("Failed to read self test: %v", err)
+	}
+	res, out, err := _run(t, string(source))
+	if err != nil {
+		t.Fatalf("Self test failed with err: %v", err)
+	}
+	if res != value.Logic(true) {
+		t.Errorf("Expected self test to return true but got %q, output:\n%s", res, out)
+	}
+	if !strings.Contains(out, "[i] Total tests count: 106") {
+		t.Errorf("Unexpected self test output:\n%s", out)
+	}
+}
+
+func TestInterpolatedString(t *testing.T) {
+	_expect(t, "$n = 21; = \"value ${$n * 2}, ${$n > 1} ${null}!\";", "value 42, true !")
+	_expect(t, "$n = 1; = \"$n \\${$n}\";", "$n ${$n}")
+	_expect(t, "func f($a) { = \"<${$a}>\"; } = \"${f(\"${1 + 1}\")}\";", "<2>")
+	_, _, err := _run(t, "= \"${1 / 0}\";")
+	if e, ok := err.(*Exception); !ok || e.Code != CodeDivisionByZero {
+		t.Errorf("Expected division by zero but got %v", err)
+	}
+}
@@ -2,8 +2,21 @@ package lang
 
 // Key words
 const (
-	KwTrue  = "true"
-	KwFalse = "false"
+	KwTrue   = "true"
+	KwFalse  = "false"
+	KwNull   = "null"
+	KwFunc   = "func"
+	KwIf     = "if"
+	KwElse   = "else"
+	KwWhile  = "while"
+	KwDo     = "do"
+	KwFor    = "for"
+	KwSwitch = "switch"
+	KwCase   = "case"
+	KwTry    = "try"
+	KwCatch  = "catch"
+	KwReturn = "return"
+	KwThrow  = "throw"
 )
 
 // Operators
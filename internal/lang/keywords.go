@@ -0,0 +1,22 @@
+package lang
+
+// Table of reserved words of the language, true, false and null are literals
+var Keywords = []string{
+	KwTrue, KwFalse, KwNull, KwFunc, KwIf, KwElse, KwWhile, KwDo, KwFor,
+	KwSwitch, KwCase, KwTry, KwCatch, KwReturn, KwThrow,
+}
+
+var keywordIndex = indexKeywords(Keywords)
+
+func indexKeywords(keywords []string) map[string]bool {
+	index := make(map[string]bool, len(keywords))
+	for _, kw := range keywords {
+		index[kw] = true
+	}
+	return index
+}
+
+// Returns true if word is reserved and can not be used as name
+func IsKeyword(word string) bool {
+	return keywordIndex[word]
+}
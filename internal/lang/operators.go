@@ -0,0 +1,95 @@
+package lang
+
+// Position of operator relative to its operands
+type Fixity uint8
+
+const (
+	Infix   Fixity = iota // a + b
+	Prefix                // -a, !a, ++$a
+	Postfix               // $a++
+)
+
+type Associativity uint8
+
+const (
+	LeftAssoc  Associativity = iota // a - b - c == (a - b) - c
+	RightAssoc                      // $a = $b = c == $a = ($b = c)
+)
+
+// Operator priorities from lowest to highest
+const (
+	PrecLowest         = iota
+	PrecAssignment     // = += -= *= /= %=
+	PrecOr             // ||
+	PrecAnd            // &&
+	PrecNot            // !a (binds weaker than comparison, so !1 > 2 is !(1 > 2))
+	PrecEquality       // == !=
+	PrecRelational     // < <= > >=
+	PrecAdditive       // + -
+	PrecMultiplicative // * / %
+	PrecUnary          // -a +a ++$a --$a
+	PrecPostfix        // $a++ $a-- and calls
+)
+
+type Operator struct {
+	Text          string
+	Fixity        Fixity
+	Precedence    int
+	Associativity Associativity
+}
+
+// Returns true if operator assigns value to its left operand
+func (o Operator) IsAssignment() bool {
+	return o.Fixity == Infix && o.Precedence == PrecAssignment
+}
+
+// Table of all operators of the language
+var Operators = []Operator{
+	{OpAssign, Infix, PrecAssignment, RightAssoc},
+	{OpPlusAssign, Infix, PrecAssignment, RightAssoc},
+	{OpMinusAssign, Infix, PrecAssignment, RightAssoc},
+	{OpMultiplyAssign, Infix, PrecAssignment, RightAssoc},
+	{OpDivideAssign, Infix, PrecAssignment, RightAssoc},
+	{OpModuloAssign, Infix, PrecAssignment, RightAssoc},
+	{OpOr, Infix, PrecOr, LeftAssoc},
+	{OpAnd, Infix, PrecAnd, LeftAssoc},
+	{OpNot, Prefix, PrecNot, RightAssoc},
+	{OpEquals, Infix, PrecEquality, LeftAssoc},
+	{OpNotEquals, Infix, PrecEquality, LeftAssoc},
+	{OpLesserThan, Infix, PrecRelational, LeftAssoc},
+	{OpLesserThanOrEquals, Infix, PrecRelational, LeftAssoc},
+	{OpGreaterThan, Infix, PrecRelational, LeftAssoc},
+	{OpGreaterThanOrEquals, Infix, PrecRelational, LeftAssoc},
+	{OpPlus, Infix, PrecAdditive, LeftAssoc},
+	{OpMinus, Infix, PrecAdditive, LeftAssoc},
+	{OpMultiply, Infix, PrecMultiplicative, LeftAssoc},
+	{OpDivision, Infix, PrecMultiplicative, LeftAssoc},
+	{OpModulo, Infix, PrecMultiplicative, LeftAssoc},
+	{OpMinus, Prefix, PrecUnary, RightAssoc},
+	{OpPlus, Prefix, PrecUnary, RightAssoc},
+	{OpIncrement, Prefix, PrecUnary, RightAssoc},
+	{OpDecrement, Prefix, PrecUnary, RightAssoc},
+	{OpIncrement, Postfix, PrecPostfix, LeftAssoc},
+	{OpDecrement, Postfix, PrecPostfix, LeftAssoc},
+}
+
+type operatorKey struct {
+	text   string
+	fixity Fixity
+}
+
+var operatorIndex = indexOperators(Operators)
+
+func indexOperators(operators []Operator) map[operatorKey]Operator {
+	index := make(map[operatorKey]Operator, len(operators))
+	for _, o := range operators {
+		index[operatorKey{o.Text, o.Fixity}] = o
+	}
+	return index
+}
+
+// Finds operator by its text and position relative to operands
+func LookupOperator(text string, fixity Fixity) (Operator, bool) {
+	o, ok := operatorIndex[operatorKey{text, fixity}]
+	return o, ok
+}
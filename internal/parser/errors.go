@@ -0,0 +1,39 @@
+package parser
+
+import "fmt"
+
+type ParserError struct {
+	fileName string
+	message  string
+	line     uint32
+	col      uint32
+	cause    error
+}
+
+func NewParserError(fileName string, message string, line uint32, col uint32, previous error) ParserError {
+	return ParserError{fileName, message, line, col, previous}
+}
+
+func (pe ParserError) Error() string {
+	return fmt.Sprintf("Error in file %s: %s\nAt line %d; col: %d", pe.fileName, pe.message, pe.line, pe.col)
+}
+
+func (pe ParserError) Cause() error {
+	return pe.cause
+}
+
+func (pe ParserError) FileName() string {
+	return pe.fileName
+}
+
+func (pe ParserError) Message() string {
+	return pe.message
+}
+
+func (pe ParserError) Line() uint32 {
+	return pe.line
+}
+
+func (pe ParserError) Col() uint32 {
+	return pe.col
+}
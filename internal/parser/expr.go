@@ -0,0 +1,224 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/Allexy/fishes/internal/ast"
+	"github.com/Allexy/fishes/internal/lang"
+	"github.com/Allexy/fishes/internal/tokenizer"
+)
+
+// Parses expression, assignment has the lowest priority
+func (p *Parser) parseExpr() (ast.Expr, error) {
+	return p.parseExprPrec(lang.PrecLowest + 1)
+}
+
+// Parses expression consisting of operators with priority not lower than given one
+func (p *Parser) parseExprPrec(minPrec int) (ast.Expr, error) {
+	x, err := p.parsePrefix(minPrec)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.current()
+		if t.Token == tokenizer.TokenOpenParen {
+			// call binds as postfix operator
+			if lang.PrecPostfix < minPrec {
+				return x, nil
+			}
+			args, err := p.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			x = &ast.CallExpr{Fun: x, Args: args, Finish: p.prevEnd()}
+			continue
+		}
+		if t.Token != tokenizer.TokenOperator && t.Token != tokenizer.TokenAssignment {
+			return x, nil
+		}
+		if op, ok := lang.LookupOperator(t.Text, lang.Postfix); ok {
+			if op.Precedence < minPrec {
+				return x, nil
+			}
+			v, ok := x.(*ast.Variable)
+			if !ok {
+				return nil, p.errorAt(t, "Operator "+t.Text+" can be applied only to variable")
+			}
+			p.next()
+			x = &ast.IncDecExpr{Start: v.Start, Op: t.Text, X: v, Postfix: true, Finish: ast.EndOf(p.fileSet, t)}
+			continue
+		}
+		op, ok := lang.LookupOperator(t.Text, lang.Infix)
+		if !ok {
+			return nil, p.unexpected("binary operator")
+		}
+		if op.Precedence < minPrec {
+			return x, nil
+		}
+		nextPrec := op.Precedence + 1
+		if op.Associativity == lang.RightAssoc {
+			nextPrec = op.Precedence
+		}
+		if op.IsAssignment() {
+			lhs, ok := x.(*ast.Variable)
+			if !ok {
+				return nil, p.errorAt(t, "Left side of assignment must be a variable")
+			}
+			p.next()
+			rhs, err := p.parseExprPrec(nextPrec)
+			if err != nil {
+				return nil, err
+			}
+			x = &ast.AssignExpr{Lhs: lhs, Op: op.Text, Rhs: rhs}
+			continue
+		}
+		p.next()
+		y, err := p.parseExprPrec(nextPrec)
+		if err != nil {
+			return nil, err
+		}
+		x = &ast.BinaryExpr{X: x, OpPos: ast.PositionOf(p.fileSet, t), Op: op.Text, Y: y}
+	}
+}
+
+// Parses operand optionally preceded by prefix operator, operand of prefix operator
+// does not take operators with priority lower than the one of enclosing expression
+func (p *Parser) parsePrefix(minPrec int) (ast.Expr, error) {
+	t := p.current()
+	if t.Token != tokenizer.TokenOperator {
+		return p.parsePrimary()
+	}
+	op, ok := lang.LookupOperator(t.Text, lang.Prefix)
+	if !ok {
+		return nil, p.unexpected("expression")
+	}
+	p.next()
+	if op.Text == lang.OpIncrement || op.Text == lang.OpDecrement {
+		v, err := p.expect(tokenizer.TokenVariable, "variable after "+op.Text)
+		if err != nil {
+			return nil, err
+		}
+		x := p.variableOf(v)
+		return &ast.IncDecExpr{Start: ast.PositionOf(p.fileSet, t), Op: op.Text, X: x, Finish: x.End()}, nil
+	}
+	prec := op.Precedence
+	if prec < minPrec {
+		prec = minPrec
+	}
+	x, err := p.parseExprPrec(prec)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.UnaryExpr{Start: ast.PositionOf(p.fileSet, t), Op: op.Text, X: x}, nil
+}
+
+// Returns source text of numerical literal, it is known only if tokens have trivia;
+// it is empty if sign is separated from number by white spaces or comments
+func numberSpelling(t *tokenizer.Token) string {
+	if strings.IndexFunc(t.Raw, unicode.IsSpace) >= 0 || strings.ContainsAny(t.Raw, "#/") {
+		return ""
+	}
+	return t.Raw
+}
+
+// Parses call arguments, trailing coma means omitted argument
+func (p *Parser) parseArgs() ([]ast.Expr, error) {
+	p.next() // step over "("
+	args := make([]ast.Expr, 0, 4)
+	for !p.is(tokenizer.TokenCloseParen) {
+		x, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, x)
+		if p.is(tokenizer.TokenComa) {
+			p.next()
+		} else if !p.is(tokenizer.TokenCloseParen) {
+			return nil, p.unexpected("\",\" or \")\"")
+		}
+	}
+	p.next()
+	return args, nil
+}
+
+func (p *Parser) parsePrimary() (ast.Expr, error) {
+	t := p.current()
+	switch t.Token {
+	case tokenizer.TokenNumber:
+		p.next()
+		return &ast.NumberLit{Start: ast.PositionOf(p.fileSet, t), Text: t.Text, Raw: numberSpelling(t), Value: t.Number(), Finish: ast.EndOf(p.fileSet, t)}, nil
+	case tokenizer.TokenString:
+		p.next()
+		raw := strings.HasPrefix(t.Raw, "`")
+		return &ast.StringLit{Start: ast.PositionOf(p.fileSet, t), Value: t.Text, Raw: raw, Finish: ast.EndOf(p.fileSet, t)}, nil
+	case tokenizer.TokenStringHead:
+		return p.parseInterpolatedString()
+	case tokenizer.TokenLogic:
+		p.next()
+		return &ast.LogicLit{Start: ast.PositionOf(p.fileSet, t), Value: t.Logic(), Finish: ast.EndOf(p.fileSet, t)}, nil
+	case tokenizer.TokenNull:
+		p.next()
+		return &ast.NullLit{Start: ast.PositionOf(p.fileSet, t), Finish: ast.EndOf(p.fileSet, t)}, nil
+	case tokenizer.TokenVariable:
+		p.next()
+		return p.variableOf(t), nil
+	case tokenizer.TokenAt:
+		p.next()
+		name, err := p.expectName("function name after \"@\"")
+		if err != nil {
+			return nil, err
+		}
+		return &ast.FuncRef{Start: ast.PositionOf(p.fileSet, t), Name: name.Text, Finish: ast.EndOf(p.fileSet, name)}, nil
+	case tokenizer.TokenOpenParen:
+		p.next()
+		x, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenizer.TokenCloseParen, "\")\""); err != nil {
+			return nil, err
+		}
+		return &ast.ParenExpr{Start: ast.PositionOf(p.fileSet, t), X: x, Finish: p.prevEnd()}, nil
+	case tokenizer.TokenKeyword:
+		switch t.Text {
+		case lang.KwFunc:
+			p.next()
+			params, err := p.parseParams()
+			if err != nil {
+				return nil, err
+			}
+			body, err := p.parseBlock()
+			if err != nil {
+				return nil, err
+			}
+			return &ast.FuncLit{Start: ast.PositionOf(p.fileSet, t), Params: params, Body: body}, nil
+		}
+	case tokenizer.TokenWord:
+		p.next()
+		return p.identOf(t), nil
+	}
+	return nil, p.unexpected("expression")
+}
+
+// Parses string with embedded expressions: "text ${expression} text"
+func (p *Parser) parseInterpolatedString() (ast.Expr, error) {
+	x := &ast.InterpolatedString{Start: ast.PositionOf(p.fileSet, p.current())}
+	for {
+		t := p.current()
+		x.Parts = append(x.Parts, &ast.StringLit{Start: ast.PositionOf(p.fileSet, t), Value: t.Text, Finish: ast.EndOf(p.fileSet, t)})
+		p.next()
+		if t.Token == tokenizer.TokenStringTail {
+			x.Finish = ast.EndOf(p.fileSet, t)
+			return x, nil
+		}
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		x.Exprs = append(x.Exprs, e)
+		if !p.is(tokenizer.TokenStringMiddle) && !p.is(tokenizer.TokenStringTail) {
+			return nil, p.unexpected("\"}\" after expression embedded into string")
+		}
+	}
+}
@@ -0,0 +1,443 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/Allexy/fishes/internal/ast"
+	"github.com/Allexy/fishes/internal/lang"
+	"github.com/Allexy/fishes/internal/tokenizer"
+)
+
+type Parser struct {
+	tw      tokenizer.TokenWalker
+	fileSet *tokenizer.FileSet // resolves positions of tokens
+}
+
+func NewParser(tw tokenizer.TokenWalker) *Parser {
+	return &Parser{tw: tw, fileSet: tw.FileSet()}
+}
+
+// Parses whole token stream into program
+func (p *Parser) Parse() (*ast.Program, error) {
+	if p.is(tokenizer.TokenBOF) {
+		p.next()
+	}
+	program := &ast.Program{Start: ast.PositionOf(p.fileSet, p.current()), Stmts: make([]ast.Stmt, 0, 64), Comments: p.comments()}
+	for !p.is(tokenizer.TokenEOF) {
+		var (
+			stmt ast.Stmt
+			err  error
+		)
+		if p.is(tokenizer.TokenAt) || p.isFuncDecl() {
+			stmt, err = p.parseFuncDecl()
+		} else {
+			stmt, err = p.parseStmt()
+		}
+		if err != nil {
+			return nil, err
+		}
+		program.Stmts = append(program.Stmts, stmt)
+	}
+	program.Finish = ast.PositionOf(p.fileSet, p.current())
+	return program, nil
+}
+
+// Returns comments kept as leading trivia of tokens from current one to the end
+func (p *Parser) comments() []*ast.Comment {
+	var comments []*ast.Comment
+	for i := 0; p.tw.CanMove(i); i++ {
+		leading := p.tw.Get(i).Leading
+		for j := range leading {
+			t := &leading[j]
+			if t.Token == tokenizer.TokenComment || t.Token == tokenizer.TokenMultilineComment {
+				text := strings.TrimRightFunc(t.Raw, unicode.IsSpace)
+				comments = append(comments, &ast.Comment{Start: ast.PositionOf(p.fileSet, t), Text: text, Finish: ast.EndOf(p.fileSet, t)})
+			}
+		}
+	}
+	return comments
+}
+
+// Parses function declaration including its decorators
+func (p *Parser) parseFuncDecl() (*ast.FuncDecl, error) {
+	decl := &ast.FuncDecl{Start: ast.PositionOf(p.fileSet, p.current())}
+	for p.is(tokenizer.TokenAt) {
+		p.next()
+		name, err := p.expectName("decorator name")
+		if err != nil {
+			return nil, err
+		}
+		decl.Decorators = append(decl.Decorators, p.identOf(name))
+	}
+	if !p.isKeyword(lang.KwFunc) {
+		return nil, p.unexpected("function declaration")
+	}
+	p.next()
+	name, err := p.expectName("function name")
+	if err != nil {
+		return nil, err
+	}
+	decl.Name = p.identOf(name)
+	if decl.Params, err = p.parseParams(); err != nil {
+		return nil, err
+	}
+	if decl.Body, err = p.parseBlock(); err != nil {
+		return nil, err
+	}
+	return decl, nil
+}
+
+// Parses list of function parameters: ($a, $b, ...)
+func (p *Parser) parseParams() ([]*ast.Variable, error) {
+	if _, err := p.expect(tokenizer.TokenOpenParen, "\"(\""); err != nil {
+		return nil, err
+	}
+	params := make([]*ast.Variable, 0, 4)
+	for !p.is(tokenizer.TokenCloseParen) {
+		if len(params) > 0 {
+			if _, err := p.expect(tokenizer.TokenComa, "\",\" or \")\""); err != nil {
+				return nil, err
+			}
+		}
+		v, err := p.expect(tokenizer.TokenVariable, "parameter")
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, p.variableOf(v))
+	}
+	p.next()
+	return params, nil
+}
+
+// Parses block: { Stmts... }
+func (p *Parser) parseBlock() (*ast.BlockStmt, error) {
+	open, err := p.expect(tokenizer.TokenOpenBrace, "\"{\"")
+	if err != nil {
+		return nil, err
+	}
+	block := &ast.BlockStmt{Start: ast.PositionOf(p.fileSet, open), Stmts: make([]ast.Stmt, 0, 8)}
+	for !p.is(tokenizer.TokenCloseBrace) {
+		if p.is(tokenizer.TokenEOF) {
+			return nil, p.unexpected("\"}\"")
+		}
+		stmt, err := p.parseStmt()
+		if err != nil {
+			return nil, err
+		}
+		block.Stmts = append(block.Stmts, stmt)
+	}
+	p.next()
+	block.Finish = p.prevEnd()
+	return block, nil
+}
+
+func (p *Parser) parseStmt() (ast.Stmt, error) {
+	t := p.current()
+	switch t.Token {
+	case tokenizer.TokenOpenBrace:
+		return p.parseBlock()
+	case tokenizer.TokenAssignment:
+		// "=" is short for "return"
+		p.next()
+		return p.parseReturnTail(t, true)
+	case tokenizer.TokenKeyword:
+		switch t.Text {
+		case lang.KwIf:
+			return p.parseIf()
+		case lang.KwWhile:
+			return p.parseWhile()
+		case lang.KwDo:
+			return p.parseDoWhile()
+		case lang.KwFor:
+			return p.parseFor()
+		case lang.KwSwitch:
+			return p.parseSwitch()
+		case lang.KwTry:
+			return p.parseTry()
+		case lang.KwReturn:
+			p.next()
+			return p.parseReturnTail(t, false)
+		case lang.KwThrow:
+			p.next()
+			value, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(tokenizer.TokenSemicolon, "\";\""); err != nil {
+				return nil, err
+			}
+			return &ast.ThrowStmt{Start: ast.PositionOf(p.fileSet, t), Value: value, Finish: p.prevEnd()}, nil
+		case lang.KwElse, lang.KwCase, lang.KwCatch:
+			return nil, p.unexpected("statement")
+		case lang.KwFunc:
+			if p.isFuncDecl() {
+				return nil, p.errorAt(t, "Function declaration is allowed only at top level")
+			}
+		}
+	}
+	x, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokenizer.TokenSemicolon, "\";\""); err != nil {
+		return nil, err
+	}
+	return &ast.ExprStmt{X: x, Finish: p.prevEnd()}, nil
+}
+
+// Parses rest of return statement after "return" or "="
+func (p *Parser) parseReturnTail(start *tokenizer.Token, short bool) (ast.Stmt, error) {
+	stmt := &ast.ReturnStmt{Start: ast.PositionOf(p.fileSet, start), Short: short}
+	if !p.is(tokenizer.TokenSemicolon) {
+		result, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Result = result
+	}
+	if _, err := p.expect(tokenizer.TokenSemicolon, "\";\""); err != nil {
+		return nil, err
+	}
+	stmt.Finish = p.prevEnd()
+	return stmt, nil
+}
+
+func (p *Parser) parseIf() (ast.Stmt, error) {
+	stmt := &ast.IfStmt{Start: ast.PositionOf(p.fileSet, p.current())}
+	p.next()
+	var err error
+	if stmt.Cond, err = p.parseCondition(); err != nil {
+		return nil, err
+	}
+	if stmt.Body, err = p.parseBlock(); err != nil {
+		return nil, err
+	}
+	if p.isKeyword(lang.KwElse) {
+		p.next()
+		if p.isKeyword(lang.KwIf) {
+			stmt.Else, err = p.parseIf()
+		} else {
+			stmt.Else, err = p.parseBlock()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stmt, nil
+}
+
+func (p *Parser) parseWhile() (ast.Stmt, error) {
+	stmt := &ast.WhileStmt{Start: ast.PositionOf(p.fileSet, p.current())}
+	p.next()
+	var err error
+	if stmt.Cond, err = p.parseCondition(); err != nil {
+		return nil, err
+	}
+	if stmt.Body, err = p.parseBlock(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+func (p *Parser) parseDoWhile() (ast.Stmt, error) {
+	stmt := &ast.DoWhileStmt{Start: ast.PositionOf(p.fileSet, p.current())}
+	p.next()
+	var err error
+	if stmt.Body, err = p.parseBlock(); err != nil {
+		return nil, err
+	}
+	if !p.isKeyword(lang.KwWhile) {
+		return nil, p.unexpected("\"while\"")
+	}
+	p.next()
+	if stmt.Cond, err = p.parseCondition(); err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokenizer.TokenSemicolon, "\";\""); err != nil {
+		return nil, err
+	}
+	stmt.Finish = p.prevEnd()
+	return stmt, nil
+}
+
+func (p *Parser) parseFor() (ast.Stmt, error) {
+	stmt := &ast.ForStmt{Start: ast.PositionOf(p.fileSet, p.current())}
+	p.next()
+	if _, err := p.expect(tokenizer.TokenOpenParen, "\"(\""); err != nil {
+		return nil, err
+	}
+	parts := [3]ast.Expr{}
+	terminators := [3]tokenizer.TokenType{tokenizer.TokenSemicolon, tokenizer.TokenSemicolon, tokenizer.TokenCloseParen}
+	expected := [3]string{"\";\"", "\";\"", "\")\""}
+	for i, terminator := range terminators {
+		if !p.is(terminator) {
+			x, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			parts[i] = x
+		}
+		if _, err := p.expect(terminator, expected[i]); err != nil {
+			return nil, err
+		}
+	}
+	stmt.Init, stmt.Cond, stmt.Post = parts[0], parts[1], parts[2]
+	var err error
+	if stmt.Body, err = p.parseBlock(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+func (p *Parser) parseSwitch() (ast.Stmt, error) {
+	stmt := &ast.SwitchStmt{Start: ast.PositionOf(p.fileSet, p.current())}
+	p.next()
+	var err error
+	if stmt.Tag, err = p.parseCondition(); err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokenizer.TokenOpenBrace, "\"{\""); err != nil {
+		return nil, err
+	}
+	for !p.is(tokenizer.TokenCloseBrace) {
+		if !p.isKeyword(lang.KwCase) {
+			return nil, p.unexpected("\"case\" or \"}\"")
+		}
+		clause := &ast.CaseClause{Start: ast.PositionOf(p.fileSet, p.current())}
+		p.next()
+		if clause.Value, err = p.parseCondition(); err != nil {
+			return nil, err
+		}
+		if clause.Body, err = p.parseBlock(); err != nil {
+			return nil, err
+		}
+		stmt.Cases = append(stmt.Cases, clause)
+	}
+	p.next()
+	stmt.Finish = p.prevEnd()
+	return stmt, nil
+}
+
+func (p *Parser) parseTry() (ast.Stmt, error) {
+	stmt := &ast.TryStmt{Start: ast.PositionOf(p.fileSet, p.current())}
+	p.next()
+	var err error
+	if stmt.Body, err = p.parseBlock(); err != nil {
+		return nil, err
+	}
+	if !p.isKeyword(lang.KwCatch) {
+		return nil, p.unexpected("\"catch\"")
+	}
+	p.next()
+	params, err := p.parseParams()
+	if err != nil {
+		return nil, err
+	}
+	if len(params) > 2 {
+		return nil, p.errorAt(p.tw.Get(-1), "Catch accepts at most two parameters: code and message")
+	}
+	if len(params) > 0 {
+		stmt.Code = params[0]
+	}
+	if len(params) > 1 {
+		stmt.Msg = params[1]
+	}
+	if stmt.Catch, err = p.parseBlock(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// Parses expression in parentheses after if, while, switch and case
+func (p *Parser) parseCondition() (ast.Expr, error) {
+	if _, err := p.expect(tokenizer.TokenOpenParen, "\"(\""); err != nil {
+		return nil, err
+	}
+	x, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokenizer.TokenCloseParen, "\")\""); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// Returns current token
+func (p *Parser) current() *tokenizer.Token {
+	return p.tw.Get(0)
+}
+
+// Steps to the next token, never steps over EOF
+func (p *Parser) next() {
+	if !p.is(tokenizer.TokenEOF) {
+		p.tw.Move(1)
+	}
+}
+
+// Returns true if current token has given type
+func (p *Parser) is(tt tokenizer.TokenType) bool {
+	t := p.current()
+	return t != nil && t.Token == tt
+}
+
+// Returns true if current token is given keyword
+func (p *Parser) isKeyword(word string) bool {
+	return p.is(tokenizer.TokenKeyword) && p.current().Text == word
+}
+
+// Returns true if current token starts named function declaration
+func (p *Parser) isFuncDecl() bool {
+	if !p.isKeyword(lang.KwFunc) {
+		return false
+	}
+	name := p.tw.Get(1)
+	return name != nil && (name.Token == tokenizer.TokenWord || isReserved(name))
+}
+
+// Returns current token and steps over it if it has expected type
+func (p *Parser) expect(tt tokenizer.TokenType, what string) (*tokenizer.Token, error) {
+	if !p.is(tt) {
+		return nil, p.unexpected(what)
+	}
+	t := p.current()
+	p.next()
+	return t, nil
+}
+
+// Returns current token and steps over it if it is name of function,
+// reserved words are reported with clear error instead of unexpected token
+func (p *Parser) expectName(what string) (*tokenizer.Token, error) {
+	if t := p.current(); isReserved(t) {
+		return nil, p.errorAt(t, fmt.Sprintf("Reserved word %q can not be used as %s", t.Text, what))
+	}
+	return p.expect(tokenizer.TokenWord, what)
+}
+
+// Returns position immediately after previous token
+func (p *Parser) prevEnd() ast.Position {
+	return ast.EndOf(p.fileSet, p.tw.Get(-1))
+}
+
+func (p *Parser) unexpected(what string) error {
+	return p.errorAt(p.current(), fmt.Sprintf("Unexpected token %s, expected %s", p.current().Describe(p.fileSet), what))
+}
+
+func (p *Parser) errorAt(t *tokenizer.Token, message string) error {
+	pos := p.fileSet.Position(t.Pos)
+	return NewParserError(pos.Filename, message, pos.Line, pos.Col, nil)
+}
+
+func isReserved(t *tokenizer.Token) bool {
+	return t.Token == tokenizer.TokenKeyword || t.Token == tokenizer.TokenLogic || t.Token == tokenizer.TokenNull
+}
+
+func (p *Parser) identOf(t *tokenizer.Token) *ast.Ident {
+	return &ast.Ident{Start: ast.PositionOf(p.fileSet, t), Name: t.Text, Finish: ast.EndOf(p.fileSet, t)}
+}
+
+func (p *Parser) variableOf(t *tokenizer.Token) *ast.Variable {
+	return &ast.Variable{Start: ast.PositionOf(p.fileSet, t), Name: t.Text, Finish: ast.EndOf(p.fileSet, t)}
+}
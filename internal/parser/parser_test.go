@@ -0,0 +1,318 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Allexy/fishes/internal/ast"
+	"github.com/Allexy/fishes/internal/tokenizer"
+)
+
+// Tokenizes and parses source given as string
+func _parse(s string) (*ast.Program, error) {
+	tw, err := tokenizer.NewTokenizer(strings.NewReader(s), "string").Tokenize()
+	if err != nil {
+		return nil, err
+	}
+	return NewParser(tw).Parse()
+}
+
+// Renders expression with explicit parentheses to make priorities visible
+func _render(x ast.Expr) string {
+	switch x := x.(type) {
+	case *ast.NumberLit:
+		return x.Text
+	case *ast.StringLit:
+		return fmt.Sprintf("%q", x.Value)
+	case *ast.InterpolatedString:
+		parts := make([]string, 0, len(x.Parts)+len(x.Exprs))
+		for i, part := range x.Parts {
+			parts = append(parts, _render(part))
+			if i < len(x.Exprs) {
+				parts = append(parts, _render(x.Exprs[i]))
+			}
+		}
+		return "str(" + strings.Join(parts, ", ") + ")"
+	case *ast.LogicLit:
+		return fmt.Sprint(x.Value)
+	case *ast.NullLit:
+		return "null"
+	case *ast.Ident:
+		return x.Name
+	case *ast.Variable:
+		return "$" + x.Name
+	case *ast.FuncRef:
+		return "@" + x.Name
+	case *ast.FuncLit:
+		return "func"
+	case *ast.ParenExpr:
+		return _render(x.X)
+	case *ast.CallExpr:
+		args := make([]string, len(x.Args))
+		for i, a := range x.Args {
+			args[i] = _render(a)
+		}
+		return _render(x.Fun) + "(" + strings.Join(args, ", ") + ")"
+	case *ast.UnaryExpr:
+		return "(" + x.Op + _render(x.X) + ")"
+	case *ast.IncDecExpr:
+		if x.Postfix {
+			return "(" + _render(x.X) + x.Op + ")"
+		}
+		return "(" + x.Op + _render(x.X) + ")"
+	case *ast.BinaryExpr:
+		return "(" + _render(x.X) + " " + x.Op + " " + _render(x.Y) + ")"
+	case *ast.AssignExpr:
+		return "(" + _render(x.Lhs) + " " + x.Op + " " + _render(x.Rhs) + ")"
+	}
+	return "?"
+}
+
+// Parses single expression statement and renders it
+func _expr(t *testing.T, s string) string {
+	program, err := _parse(s + ";")
+	if err != nil {
+		t.Fatalf("Parsing of %q failed with err: %v", s, err)
+	}
+	if len(program.Stmts) != 1 {
+		t.Fatalf("Expected 1 statement but got %d", len(program.Stmts))
+	}
+	stmt, ok := program.Stmts[0].(*ast.ExprStmt)
+	if !ok {
+		t.Fatalf("Expected expression statement but got %T", program.Stmts[0])
+	}
+	return _render(stmt.X)
+}
+
+func TestSelfTest(t *testing.T) {
+	f, err := os.Open("../../self_test.fs")
+	if err != nil {
+		t.Fatalf("Failed to open self test: %v", err)
+	}
+	defer f.Close()
+	tw, err := tokenizer.NewTokenizer(f, "self_test.fs").Tokenize()
+	if err != nil {
+		t.Fatalf("Tokenization failed with err: %v", err)
+	}
+	program, err := NewParser(tw).Parse()
+	if err != nil {
+		t.Fatalf("Parsing failed with err: %v", err)
+	}
+	funcs := program.Funcs()
+	if len(funcs) < 80 {
+		t.Errorf("Expected more than 80 functions but got %d", len(funcs))
+	}
+	last := program.Stmts[len(program.Stmts)-1]
+	if _, ok := last.(*ast.TryStmt); !ok {
+		t.Errorf("Expected last statement is try/catch but got %T", last)
+	}
+}
+
+func TestFuncDecl(t *testing.T) {
+	program, err := _parse("@dec1 @dec2 func sum($a, $b) { return $a + $b; }")
+	if err != nil {
+		t.Fatalf("Parsing failed with err: %v", err)
+	}
+	decl, ok := program.Stmts[0].(*ast.FuncDecl)
+	if !ok {
+		t.Fatalf("Expected function declaration but got %T", program.Stmts[0])
+	}
+	if decl.Name.Name != "sum" || len(decl.Params) != 2 || len(decl.Decorators) != 2 {
+		t.Errorf("Unexpected declaration %+v", decl)
+	}
+	if decl.Decorators[0].Name != "dec1" || decl.Decorators[1].Name != "dec2" {
+		t.Errorf("Unexpected decorators order")
+	}
+	ret, ok := decl.Body.Stmts[0].(*ast.ReturnStmt)
+	if !ok || ret.Short {
+		t.Errorf("Expected return statement but got %T", decl.Body.Stmts[0])
+	}
+}
+
+func TestNestedFuncDecl(t *testing.T) {
+	if _, err := _parse("func a() { func b() {} }"); err == nil {
+		t.Error("Expected error for nested function declaration")
+	}
+}
+
+func TestShortReturn(t *testing.T) {
+	program, err := _parse("= 1;")
+	if err != nil {
+		t.Fatalf("Parsing failed with err: %v", err)
+	}
+	ret, ok := program.Stmts[0].(*ast.ReturnStmt)
+	if !ok || !ret.Short || ret.Result == nil {
+		t.Errorf("Expected short return statement but got %+v", program.Stmts[0])
+	}
+}
+
+func TestIfElse(t *testing.T) {
+	program, err := _parse("if(true) { } else if(false) { } else { }")
+	if err != nil {
+		t.Fatalf("Parsing failed with err: %v", err)
+	}
+	stmt := program.Stmts[0].(*ast.IfStmt)
+	elseIf, ok := stmt.Else.(*ast.IfStmt)
+	if !ok {
+		t.Fatalf("Expected else if but got %T", stmt.Else)
+	}
+	if _, ok := elseIf.Else.(*ast.BlockStmt); !ok {
+		t.Errorf("Expected else block but got %T", elseIf.Else)
+	}
+}
+
+func TestLoops(t *testing.T) {
+	program, err := _parse("while($n > 0) { $n--; } do { $n++; } while($n < 10); for($a = 0; $a < 5; $a ++) { } for(;;) { }")
+	if err != nil {
+		t.Fatalf("Parsing failed with err: %v", err)
+	}
+	if _, ok := program.Stmts[0].(*ast.WhileStmt); !ok {
+		t.Errorf("Expected while but got %T", program.Stmts[0])
+	}
+	if _, ok := program.Stmts[1].(*ast.DoWhileStmt); !ok {
+		t.Errorf("Expected do/while but got %T", program.Stmts[1])
+	}
+	loop := program.Stmts[2].(*ast.ForStmt)
+	if loop.Init == nil || loop.Cond == nil || loop.Post == nil {
+		t.Errorf("Expected all parts of for loop")
+	}
+	loop = program.Stmts[3].(*ast.ForStmt)
+	if loop.Init != nil || loop.Cond != nil || loop.Post != nil {
+		t.Errorf("Expected empty parts of for loop")
+	}
+}
+
+func TestSwitch(t *testing.T) {
+	program, err := _parse("switch($a) { case(1) { } case(\"x\" + $a) { } }")
+	if err != nil {
+		t.Fatalf("Parsing failed with err: %v", err)
+	}
+	stmt := program.Stmts[0].(*ast.SwitchStmt)
+	if len(stmt.Cases) != 2 {
+		t.Errorf("Expected 2 cases but got %d", len(stmt.Cases))
+	}
+}
+
+func TestTryCatch(t *testing.T) {
+	program, err := _parse("try { throw(\"x\"); } catch($code, $msg) { }")
+	if err != nil {
+		t.Fatalf("Parsing failed with err: %v", err)
+	}
+	stmt := program.Stmts[0].(*ast.TryStmt)
+	if stmt.Code.Name != "code" || stmt.Msg.Name != "msg" {
+		t.Errorf("Unexpected catch parameters")
+	}
+	if _, ok := stmt.Body.Stmts[0].(*ast.ThrowStmt); !ok {
+		t.Errorf("Expected throw but got %T", stmt.Body.Stmts[0])
+	}
+	if _, err := _parse("try { } catch($a, $b, $c) { }"); err == nil {
+		t.Error("Expected error for too many catch parameters")
+	}
+}
+
+func TestExpressions(t *testing.T) {
+	cases := map[string]string{
+		"2 + 2 * 2":                     "(2 + (2 * 2))",
+		"1 * 2 * 3":                     "((1 * 2) * 3)",
+		"2 * 2 / 2":                     "((2 * 2) / 2)",
+		"-2 * (2 + 2) * -2":             "((-2 * (2 + 2)) * -2)",
+		"true || false && true":         "(true || (false && true))",
+		"!1 > 2 && !2 < 1":              "((!(1 > 2)) && (!(2 < 1)))",
+		"!false || false":               "((!false) || false)",
+		"$b = ++ $a":                    "($b = (++$a))",
+		"$b = $a --":                    "($b = ($a--))",
+		"$a = $b = 1":                   "($a = ($b = 1))",
+		"$res += 200":                   "($res += 200)",
+		"($test = nextTest()) != false": "(($test = nextTest()) != false)",
+		"$ref(20)(1, 2)":                "$ref(20)(1, 2)",
+		"$f = @func_returns_lambda":     "($f = @func_returns_lambda)",
+		"getEmpty(1,)":                  "getEmpty(1)",
+		"print()":                       "print()",
+		"1 + sum(1 + 1, 2 + 1) + 1":     "((1 + sum((1 + 1), (2 + 1))) + 1)",
+		"$sum = func($a, $b) { = $a; }": "($sum = func)",
+		"100 - ((10 - 10/$x) / 10)":     "(100 - ((10 - (10 / $x)) / 10))",
+		"10 * $x + 20 * $x":             "((10 * $x) + (20 * $x))",
+		"$a == null":                    "($a == null)",
+		"1 < 2 == true":                 "((1 < 2) == true)",
+		"-$x * 2":                       "((-$x) * 2)",
+		"$a = 1 || 2":                   "($a = (1 || 2))",
+		"!$a == $b":                     "(!($a == $b))",
+		"2 * 3 % 4":                     "((2 * 3) % 4)",
+		"10 - 2 - 3":                    "((10 - 2) - 3)",
+		"$a *= $b += 2":                 "($a *= ($b += 2))",
+		"-$f(1)":                        "(-$f(1))",
+		"2 * !0 + 3":                    "((2 * (!0)) + 3)",
+		"1 + !0 == 1":                   "((1 + (!0)) == 1)",
+		"!1 > 2":                        "(!(1 > 2))",
+		"$b = $a++ - 1":                 "($b = (($a++) - 1))",
+		"$a-- + 2":                      "(($a--) + 2)",
+		"-- $a - 1":                     "((--$a) - 1)",
+	}
+	for source, expected := range cases {
+		if actual := _expr(t, source); actual != expected {
+			t.Errorf("Expected %q for %q but got %q", expected, source, actual)
+		}
+	}
+}
+
+func TestInvalidAssignment(t *testing.T) {
+	if _, err := _parse("1 = 2;"); err == nil {
+		t.Error("Expected error for assignment to literal")
+	}
+}
+
+func TestInvalidPostfix(t *testing.T) {
+	if _, err := _parse("f() ++;"); err == nil {
+		t.Error("Expected error for increment of call result")
+	}
+}
+
+func TestReservedNames(t *testing.T) {
+	cases := map[string]string{
+		"func while() { }":                "Reserved word \"while\" can not be used as function name\nAt line 1; col: 6",
+		"func true($a) { }":               "Reserved word \"true\" can not be used as function name\nAt line 1; col: 6",
+		"@null\nfunc f() { }":             "Reserved word \"null\" can not be used as decorator name\nAt line 1; col: 2",
+		"$f = @return;":                   "Reserved word \"return\" can not be used as function name after \"@\"\nAt line 1; col: 7",
+		"func f() { func catch($a) { } }": "Function declaration is allowed only at top level\nAt line 1; col: 12",
+		"$a = if;":                        "Unexpected token KEYWORD(\"if\"@1:6), expected expression\nAt line 1; col: 6",
+	}
+	for source, expected := range cases {
+		_, err := _parse(source)
+		if err == nil || !strings.HasSuffix(err.Error(), expected) {
+			t.Errorf("Expected error %q for %q but got %v", expected, source, err)
+		}
+	}
+	if _, err := _parse("func whiles() { } $f = func() { = null; };"); err != nil {
+		t.Errorf("Parsing failed with err: %v", err)
+	}
+}
+
+func TestMissingSemicolon(t *testing.T) {
+	_, err := _parse("$a = 1\n$b = 2;")
+	if err == nil {
+		t.Fatal("Expected error for missing semicolon")
+	}
+	if !strings.Contains(err.Error(), "At line 2; col: 1") {
+		t.Errorf("Expected error position at line 2 but got %v", err)
+	}
+}
+
+func TestInterpolatedString(t *testing.T) {
+	cases := map[string]string{
+		`"n = ${$n * 2}!"`:         `str("n = ", ($n * 2), "!")`,
+		`"${f(1)}${"a${$b}"}" + 1`: `(str("", f(1), "", str("a", $b, ""), "") + 1)`,
+		`"$n \${n}"`:               `"$n ${n}"`,
+	}
+	for s, expected := range cases {
+		if actual := _expr(t, s); actual != expected {
+			t.Errorf("Expected %s for %s but got %s", expected, s, actual)
+		}
+	}
+	_, err := _parse(`$a = "x ${$a $b}";`)
+	expected := "Error in file string: Unexpected token VARIABLE(\"b\"@1:14), expected \"}\" after expression embedded into string\nAt line 1; col: 14"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q but got %v", expected, err)
+	}
+}
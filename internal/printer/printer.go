@@ -0,0 +1,392 @@
+// Package printer formats syntax trees as source code
+package printer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/Allexy/fishes/internal/ast"
+	"github.com/Allexy/fishes/internal/lang"
+	"github.com/Allexy/fishes/internal/tokenizer"
+)
+
+// Indentation of nested statements
+const Indent = "    "
+
+// Writes node as formatted source, only comments of program are kept: each one is written on its own line
+// before the next statement or after the statement on its line
+func Fprint(w io.Writer, node ast.Node) error {
+	p := &printer{}
+	switch n := node.(type) {
+	case *ast.Program:
+		p.comments = n.Comments
+		p.program(n)
+	case ast.Stmt:
+		p.stmt(n)
+	case ast.Expr:
+		p.expr(n)
+	default:
+		p.node(n)
+	}
+	_, err := w.Write(p.buf.Bytes())
+	return err
+}
+
+type printer struct {
+	buf      bytes.Buffer
+	depth    int
+	comments []*ast.Comment // comments which are not written yet
+}
+
+func (p *printer) write(s ...string) {
+	for _, part := range s {
+		p.buf.WriteString(part)
+	}
+}
+
+// Starts new line of statement
+func (p *printer) indent() {
+	p.write(strings.Repeat(Indent, p.depth))
+}
+
+// Top level function declarations are separated by empty lines
+func (p *printer) program(program *ast.Program) {
+	for i, stmt := range program.Stmts {
+		_, isDecl := stmt.(*ast.FuncDecl)
+		if i > 0 {
+			_, prevDecl := program.Stmts[i-1].(*ast.FuncDecl)
+			if isDecl || prevDecl {
+				p.write("\n")
+			}
+		}
+		p.stmt(stmt)
+	}
+	for len(p.comments) > 0 {
+		p.comment()
+	}
+}
+
+// Writes indented comment on its own line
+func (p *printer) comment() {
+	p.indent()
+	p.write(p.comments[0].Text, "\n")
+	p.comments = p.comments[1:]
+}
+
+// Writes comments beginning before position
+func (p *printer) commentsBefore(pos ast.Position) {
+	for len(p.comments) > 0 && before(p.comments[0].Start, pos) {
+		p.comment()
+	}
+}
+
+// Writes comments beginning on the line where statement ends
+func (p *printer) trailingComments(stmt ast.Stmt) {
+	for len(p.comments) > 0 && p.comments[0].Start.Line == stmt.End().Line {
+		p.write(" ", p.comments[0].Text)
+		p.comments = p.comments[1:]
+	}
+}
+
+func before(a, b ast.Position) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Col < b.Col
+}
+
+// Writes indented statement followed by new line, comments before statement and on its line are written too
+func (p *printer) stmt(stmt ast.Stmt) {
+	p.commentsBefore(stmt.Pos())
+	if decl, ok := stmt.(*ast.FuncDecl); ok {
+		for _, d := range decl.Decorators {
+			p.indent()
+			p.write("@", d.Name, "\n")
+		}
+	}
+	p.indent()
+	p.stmtBody(stmt)
+	p.trailingComments(stmt)
+	p.write("\n")
+}
+
+// Writes statement without indentation of its first line
+func (p *printer) stmtBody(stmt ast.Stmt) {
+	switch s := stmt.(type) {
+	case *ast.BlockStmt:
+		p.block(s)
+	case *ast.ExprStmt:
+		p.expr(s.X)
+		p.write(";")
+	case *ast.FuncDecl:
+		p.write(lang.KwFunc, " ", s.Name.Name)
+		p.params(s.Params)
+		p.write(" ")
+		p.block(s.Body)
+	case *ast.ReturnStmt:
+		switch {
+		case s.Result == nil:
+			p.write(lang.KwReturn, ";")
+		case s.Short:
+			p.write(lang.OpAssign, " ")
+			p.expr(s.Result)
+			p.write(";")
+		default:
+			p.write(lang.KwReturn, " ")
+			p.expr(s.Result)
+			p.write(";")
+		}
+	case *ast.ThrowStmt:
+		p.write(lang.KwThrow)
+		if _, ok := s.Value.(*ast.ParenExpr); !ok {
+			p.write(" ")
+		}
+		p.expr(s.Value)
+		p.write(";")
+	case *ast.IfStmt:
+		p.condition(lang.KwIf, s.Cond)
+		p.block(s.Body)
+		if s.Else != nil {
+			p.write("\n")
+			p.indent()
+			p.write(lang.KwElse, " ")
+			p.stmtBody(s.Else)
+		}
+	case *ast.WhileStmt:
+		p.condition(lang.KwWhile, s.Cond)
+		p.block(s.Body)
+	case *ast.DoWhileStmt:
+		p.write(lang.KwDo, " ")
+		p.block(s.Body)
+		p.write(" ", lang.KwWhile, "(")
+		p.expr(s.Cond)
+		p.write(");")
+	case *ast.ForStmt:
+		p.write(lang.KwFor, "(")
+		p.optExpr("", s.Init)
+		p.write(";")
+		p.optExpr(" ", s.Cond)
+		p.write(";")
+		p.optExpr(" ", s.Post)
+		p.write(") ")
+		p.block(s.Body)
+	case *ast.SwitchStmt:
+		p.condition(lang.KwSwitch, s.Tag)
+		p.write("{\n")
+		p.depth++
+		for _, c := range s.Cases {
+			p.indent()
+			p.condition(lang.KwCase, c.Value)
+			p.block(c.Body)
+			p.write("\n")
+		}
+		p.depth--
+		p.indent()
+		p.write("}")
+	case *ast.TryStmt:
+		p.write(lang.KwTry, " ")
+		p.block(s.Body)
+		p.write("\n")
+		p.indent()
+		p.write(lang.KwCatch)
+		var params []*ast.Variable
+		for _, v := range []*ast.Variable{s.Code, s.Msg} {
+			if v != nil {
+				params = append(params, v)
+			}
+		}
+		p.params(params)
+		p.write(" ")
+		p.block(s.Catch)
+	default:
+		p.node(s)
+	}
+}
+
+// Writes keyword(x) followed by space
+func (p *printer) condition(keyword string, x ast.Expr) {
+	p.write(keyword, "(")
+	p.expr(x)
+	p.write(") ")
+}
+
+func (p *printer) optExpr(prefix string, x ast.Expr) {
+	if x != nil {
+		p.write(prefix)
+		p.expr(x)
+	}
+}
+
+// Writes block, its closing brace is not followed by new line
+func (p *printer) block(b *ast.BlockStmt) {
+	p.write("{\n")
+	p.depth++
+	for _, stmt := range b.Stmts {
+		p.stmt(stmt)
+	}
+	p.commentsBefore(b.End())
+	p.depth--
+	p.indent()
+	p.write("}")
+}
+
+func (p *printer) params(params []*ast.Variable) {
+	p.write("(")
+	for i, v := range params {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.write("$", v.Name)
+	}
+	p.write(")")
+}
+
+func (p *printer) expr(x ast.Expr) {
+	switch x := x.(type) {
+	case *ast.NumberLit:
+		if x.Raw != "" {
+			p.write(x.Raw)
+		} else {
+			p.write(x.Text)
+		}
+	case *ast.StringLit:
+		if x.Raw {
+			p.write("`", x.Value, "`")
+		} else {
+			p.write(Quote(x.Value))
+		}
+	case *ast.InterpolatedString:
+		p.write(`"`)
+		for i, part := range x.Parts {
+			p.write(escape(part.Value))
+			if i < len(x.Exprs) {
+				p.write(tokenizer.InterpolationMarker)
+				p.expr(x.Exprs[i])
+				p.write("}")
+			}
+		}
+		p.write(`"`)
+	case *ast.LogicLit:
+		if x.Value {
+			p.write(lang.KwTrue)
+		} else {
+			p.write(lang.KwFalse)
+		}
+	case *ast.NullLit:
+		p.write(lang.KwNull)
+	case *ast.Ident:
+		p.write(x.Name)
+	case *ast.Variable:
+		p.write("$", x.Name)
+	case *ast.FuncRef:
+		p.write("@", x.Name)
+	case *ast.FuncLit:
+		p.write(lang.KwFunc)
+		p.params(x.Params)
+		p.write(" ")
+		p.block(x.Body)
+	case *ast.ParenExpr:
+		p.write("(")
+		p.expr(x.X)
+		p.write(")")
+	case *ast.CallExpr:
+		p.expr(x.Fun)
+		p.write("(")
+		for i, a := range x.Args {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.expr(a)
+		}
+		p.write(")")
+	case *ast.UnaryExpr:
+		p.write(x.Op)
+		if needsSpace(x.X) {
+			p.write(" ")
+		}
+		p.expr(x.X)
+	case *ast.IncDecExpr:
+		if x.Postfix {
+			p.expr(x.X)
+			p.write(" ", x.Op)
+		} else {
+			p.write(x.Op, " ")
+			p.expr(x.X)
+		}
+	case *ast.BinaryExpr:
+		p.expr(x.X)
+		p.write(" ", x.Op, " ")
+		p.expr(x.Y)
+	case *ast.AssignExpr:
+		p.expr(x.Lhs)
+		p.write(" ", x.Op, " ")
+		p.expr(x.Rhs)
+	default:
+		p.node(x)
+	}
+}
+
+// Unknown nodes are not expected, they are written as comment to keep output visible
+func (p *printer) node(n ast.Node) {
+	p.write("/* unsupported node ", n.Pos().String(), " */")
+}
+
+// Returns true if operand starting with operator must be separated from prefix operator,
+// otherwise both would be read as single operator ("- -1" would become "--1")
+func needsSpace(x ast.Expr) bool {
+	switch x := x.(type) {
+	case *ast.UnaryExpr:
+		return true
+	case *ast.IncDecExpr:
+		return !x.Postfix
+	case *ast.NumberLit:
+		return strings.HasPrefix(x.Text, "-") || strings.HasPrefix(x.Text, "+")
+	}
+	return false
+}
+
+// Returns string literal with escaped quote marks, back slashes and not printable characters
+func Quote(s string) string {
+	return `"` + escape(s) + `"`
+}
+
+// Escapes text of string literal, "${" is escaped too so it does not begin embedded expression
+func escape(s string) string {
+	var sb strings.Builder
+	for i, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case 0:
+			sb.WriteString(`\0`)
+		case '$':
+			if strings.HasPrefix(s[i:], tokenizer.InterpolationMarker) {
+				sb.WriteString(`\$`)
+			} else {
+				sb.WriteRune(r)
+			}
+		default:
+			switch {
+			case unicode.IsPrint(r):
+				sb.WriteRune(r)
+			case r > 0xFFFF:
+				fmt.Fprintf(&sb, `\U%08X`, r)
+			default:
+				fmt.Fprintf(&sb, `\u%04X`, r)
+			}
+		}
+	}
+	return sb.String()
+}
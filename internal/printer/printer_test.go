@@ -0,0 +1,166 @@
+package printer
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Allexy/fishes/internal/ast"
+	"github.com/Allexy/fishes/internal/interp"
+	"github.com/Allexy/fishes/internal/parser"
+	"github.com/Allexy/fishes/internal/tokenizer"
+	"github.com/Allexy/fishes/internal/value"
+)
+
+func _parse(t *testing.T, s string, options ...tokenizer.Option) *ast.Program {
+	tw, err := tokenizer.NewTokenizer(strings.NewReader(s), "string", options...).Tokenize()
+	if err != nil {
+		t.Fatalf("Tokenization failed with err: %v", err)
+	}
+	program, err := parser.NewParser(tw).Parse()
+	if err != nil {
+		t.Fatalf("Parsing failed with err: %v\n%s", err, s)
+	}
+	return program
+}
+
+func _format(t *testing.T, s string, options ...tokenizer.Option) string {
+	var sb strings.Builder
+	if err := Fprint(&sb, _parse(t, s, options...)); err != nil {
+		t.Fatalf("Printing failed with err: %v", err)
+	}
+	return sb.String()
+}
+
+func TestFormat(t *testing.T) {
+	source := `@dec func  f($a,$b){if($a>1){=$a;}else if(!$b){return;}else{throw("x");}}
+func dec($f){=$f;}
+$i=0;while($i<3){$i++;}do{--$i;}while($i>0);
+for(;;){}
+switch($i){case(1){print("a\"b\n");}}
+try{$s=func($x){= - -$x;};}catch($c,$m){}`
+	expected := `@dec
+func f($a, $b) {
+    if($a > 1) {
+        = $a;
+    }
+    else if(!$b) {
+        return;
+    }
+    else {
+        throw("x");
+    }
+}
+
+func dec($f) {
+    = $f;
+}
+
+$i = 0;
+while($i < 3) {
+    $i ++;
+}
+do {
+    -- $i;
+} while($i > 0);
+for(;;) {
+}
+switch($i) {
+    case(1) {
+        print("a\"b\n");
+    }
+}
+try {
+    $s = func($x) {
+        = - -$x;
+    };
+}
+catch($c, $m) {
+}
+`
+	if actual := _format(t, source); actual != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, actual)
+	}
+}
+
+func TestUnarySpacing(t *testing.T) {
+	cases := map[string]string{
+		"= - -1;":                             "= - -1;\n",
+		"= !(1);":                             "= !(1);\n",
+		"= ! !1;":                             "= ! !1;\n",
+		"= - ++ $a;":                          "= - ++ $a;\n",
+		"= 1 - -1;":                           "= 1 - -1;\n",
+		"= $a++ + $b;":                        "= $a ++ + $b;\n",
+		"= @f($a)(1);":                        "= @f($a)(1);\n",
+		"= \"a${ $b+1 }\\${c}${\"${$d}\"}\";": "= \"a${$b + 1}\\${c}${\"${$d}\"}\";\n",
+	}
+	for source, expected := range cases {
+		if actual := _format(t, source); actual != expected {
+			t.Errorf("Expected %q for %q but got %q", expected, source, actual)
+		}
+	}
+}
+
+// Formatted self test must be stable and must keep its meaning
+func TestSelfTest(t *testing.T) {
+	source, err := os.ReadFile("../../self_test.fs")
+	if err != nil {
+		t.Fatalf("Failed to read self test: %v", err)
+	}
+	formatted := _format(t, string(source))
+	if again := _format(t, formatted); again != formatted {
+		t.Error("Formatting is not idempotent")
+	}
+	in := interp.NewInterpreter(&strings.Builder{})
+	res, err := in.Run(_parse(t, formatted))
+	if err != nil {
+		t.Fatalf("Formatted self test failed with err: %v", err)
+	}
+	if res != value.Logic(true) {
+		t.Errorf("Expected formatted self test to return true but got %v", res)
+	}
+}
+
+func TestComments(t *testing.T) {
+	actual := _format(t, "if(1){# a\n$a=1;#b\n}\n#c", tokenizer.WithTrivia())
+	if expected := "if(1) {\n    # a\n    $a = 1; #b\n}\n#c\n"; actual != expected {
+		t.Errorf("Expected %q but got %q", expected, actual)
+	}
+}
+
+// Literals keep their source spelling if source is tokenized with trivia
+func TestSourceSpelling(t *testing.T) {
+	cases := map[string]string{
+		"= 0xFF+1_000_000;": "= 0xFF + 1_000_000;\n",
+		"= -0b1_01*0o17;":   "= -0b1_01 * 0o17;\n",
+		"= 1 - - 1.5e3;":    "= 1 - -1.5e3;\n",
+		"= `raw \\ text`;":  "= `raw \\ text`;\n",
+		"= `a\n${b}`;":      "= `a\n${b}`;\n",
+	}
+	for source, expected := range cases {
+		if actual := _format(t, source, tokenizer.WithTrivia()); actual != expected {
+			t.Errorf("Expected %q for %q but got %q", expected, source, actual)
+		}
+	}
+	if actual := _format(t, "= 0xFF;"); actual != "= 255;\n" {
+		t.Errorf("Expected canonical number without trivia but got %q", actual)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	cases := map[string]string{
+		"plain":                 `"plain"`,
+		"это \"текст\"":         `"это \"текст\""`,
+		"a\\b\n\t":              `"a\\b\n\t"`,
+		"\x00\x01\u200b":        `"\0\u0001\u200B"`,
+		"\U0001F41F \U000E0001": `"🐟 \U000E0001"`,
+	}
+	for s, expected := range cases {
+		if actual := Quote(s); actual != expected {
+			t.Errorf("Expected %s for %q but got %s", expected, s, actual)
+		}
+		if _format(t, "= "+expected+";") != "= "+expected+";\n" {
+			t.Errorf("Expected %s to be kept by formatting", expected)
+		}
+	}
+}
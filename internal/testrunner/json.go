@@ -0,0 +1,60 @@
+package testrunner
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Reporter writing single JSON object per line for every test
+type JSONReporter struct {
+	enc *json.Encoder
+}
+
+type jsonFailure struct {
+	Message string `json:"message"`
+	File    string `json:"file"`
+	Line    uint32 `json:"line"`
+	Col     uint32 `json:"col"`
+}
+
+type jsonRecord struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	File        string       `json:"file"`
+	Line        uint32       `json:"line"`
+	Passed      bool         `json:"passed"`
+	Duration    float64      `json:"duration"` // seconds
+	Failure     *jsonFailure `json:"failure,omitempty"`
+	Output      string       `json:"output,omitempty"`
+}
+
+func NewJSONReporter(w io.Writer) *JSONReporter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &JSONReporter{enc: enc}
+}
+
+func (r *JSONReporter) Report(res Result) error {
+	rec := jsonRecord{
+		Name:        res.Name,
+		Description: res.Description,
+		File:        res.SourceName,
+		Line:        res.Pos.Line,
+		Passed:      res.Passed,
+		Duration:    res.Duration.Seconds(),
+		Output:      res.Output,
+	}
+	if !res.Passed {
+		rec.Failure = &jsonFailure{
+			Message: res.Failure,
+			File:    res.FailurePos.SourceName,
+			Line:    res.FailurePos.Line,
+			Col:     res.FailurePos.Col,
+		}
+	}
+	return r.enc.Encode(rec)
+}
+
+func (r *JSONReporter) Finish() error {
+	return nil
+}
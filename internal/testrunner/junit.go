@@ -0,0 +1,122 @@
+package testrunner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// Reporter producing JUnit XML, document is written when all tests are finished
+type JUnitReporter struct {
+	w      io.Writer
+	suites []*junitSuite
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitProperties struct {
+	Properties []junitProperty `xml:"property"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitCase struct {
+	Name       string           `xml:"name,attr"`
+	ClassName  string           `xml:"classname,attr"`
+	Time       string           `xml:"time,attr"`
+	File       string           `xml:"file,attr"`
+	Line       uint32           `xml:"line,attr"`
+	Properties *junitProperties `xml:"properties,omitempty"`
+	Failure    *junitFailure    `xml:"failure,omitempty"`
+	SystemOut  string           `xml:"system-out,omitempty"`
+}
+
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+	seconds  float64
+}
+
+type junitSuites struct {
+	XMLName  xml.Name      `xml:"testsuites"`
+	Tests    int           `xml:"tests,attr"`
+	Failures int           `xml:"failures,attr"`
+	Time     string        `xml:"time,attr"`
+	Suites   []*junitSuite `xml:"testsuite"`
+}
+
+func NewJUnitReporter(w io.Writer) *JUnitReporter {
+	return &JUnitReporter{w: w}
+}
+
+func (r *JUnitReporter) Report(res Result) error {
+	suite := r.suite(res.SourceName)
+	c := junitCase{
+		Name:      res.Name,
+		ClassName: res.SourceName,
+		Time:      seconds(res.Duration.Seconds()),
+		File:      res.SourceName,
+		Line:      res.Pos.Line,
+		SystemOut: res.Output,
+	}
+	if res.Description != "" {
+		c.Properties = &junitProperties{[]junitProperty{{Name: "description", Value: res.Description}}}
+	}
+	if !res.Passed {
+		c.Failure = &junitFailure{
+			Message: res.Failure,
+			Text:    fmt.Sprintf("%v: %s", res.FailurePos, res.Failure),
+		}
+		suite.Failures++
+	}
+	suite.Tests++
+	suite.seconds += res.Duration.Seconds()
+	suite.Cases = append(suite.Cases, c)
+	return nil
+}
+
+func (r *JUnitReporter) Finish() error {
+	doc := junitSuites{Suites: r.suites}
+	total := 0.0
+	for _, s := range r.suites {
+		s.Time = seconds(s.seconds)
+		doc.Tests += s.Tests
+		doc.Failures += s.Failures
+		total += s.seconds
+	}
+	doc.Time = seconds(total)
+	if _, err := io.WriteString(r.w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(r.w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(r.w, "\n")
+	return err
+}
+
+// Returns suite of source file, results of the same file come one after another
+func (r *JUnitReporter) suite(sourceName string) *junitSuite {
+	if n := len(r.suites); n > 0 && r.suites[n-1].Name == sourceName {
+		return r.suites[n-1]
+	}
+	s := &junitSuite{Name: sourceName}
+	r.suites = append(r.suites, s)
+	return s
+}
+
+func seconds(s float64) string {
+	return fmt.Sprintf("%.3f", s)
+}
@@ -0,0 +1,89 @@
+package testrunner
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Report formats
+const (
+	FormatText  = "text"
+	FormatJUnit = "junit"
+	FormatTAP   = "tap"
+	FormatJSON  = "json"
+)
+
+// Receives results of tests in order of execution
+type Reporter interface {
+	Report(res Result) error
+	// Called once after the last result
+	Finish() error
+}
+
+// Creates reporter for format writing to w
+func NewReporter(format string, w io.Writer) (Reporter, error) {
+	switch format {
+	case FormatText:
+		return NewTextReporter(w), nil
+	case FormatJUnit:
+		return NewJUnitReporter(w), nil
+	case FormatTAP:
+		return NewTAPReporter(w), nil
+	case FormatJSON:
+		return NewJSONReporter(w), nil
+	}
+	return nil, fmt.Errorf("unknown report format %q", format)
+}
+
+// Human readable reporter
+type TextReporter struct {
+	w              io.Writer
+	passed, failed int
+}
+
+func NewTextReporter(w io.Writer) *TextReporter {
+	return &TextReporter{w: w}
+}
+
+func (r *TextReporter) Report(res Result) error {
+	if res.Passed {
+		r.passed++
+	} else {
+		r.failed++
+	}
+	status := "[+]"
+	if !res.Passed {
+		status = "[-]"
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s() (%.3fs)", status, res.Name, res.Duration.Seconds())
+	if res.Description != "" {
+		fmt.Fprintf(&sb, ": %s", res.Description)
+	}
+	sb.WriteByte('\n')
+	if !res.Passed {
+		fmt.Fprintf(&sb, "    %v: %s\n", res.FailurePos, res.Failure)
+		for _, line := range outputLines(res.Output) {
+			fmt.Fprintf(&sb, "    | %s\n", line)
+		}
+	}
+	_, err := io.WriteString(r.w, sb.String())
+	return err
+}
+
+func (r *TextReporter) Finish() error {
+	_, err := fmt.Fprintf(r.w, "[i] Passed: %d, failed: %d\n", r.passed, r.failed)
+	return err
+}
+
+// Splits printed output to non empty lines
+func outputLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
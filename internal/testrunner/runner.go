@@ -0,0 +1,77 @@
+package testrunner
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Allexy/fishes/internal/ast"
+	"github.com/Allexy/fishes/internal/interp"
+	"github.com/Allexy/fishes/internal/value"
+)
+
+// Outcome of single test
+type Result struct {
+	Test
+	SourceName string
+	Passed     bool
+	Failure    string       // failure message, empty for passed test
+	FailurePos ast.Position // where test failed
+	Duration   time.Duration
+	Output     string // printed by test
+}
+
+// Runs test functions, each test gets its own interpreter so tests do not share state
+type Runner struct {
+	Filter *regexp.Regexp // runs only tests with matching names, nil runs all tests
+}
+
+func NewRunner(filter *regexp.Regexp) *Runner {
+	return &Runner{Filter: filter}
+}
+
+// Runs tests of suite which match filter
+func (r *Runner) Run(suite *Suite) []Result {
+	results := make([]Result, 0, len(suite.Tests))
+	for _, test := range suite.Tests {
+		if r.Filter != nil && !r.Filter.MatchString(test.Name) {
+			continue
+		}
+		results = append(results, r.runTest(suite, test))
+	}
+	return results
+}
+
+func (r *Runner) runTest(suite *Suite, test Test) Result {
+	var out strings.Builder
+	res := Result{Test: test, SourceName: suite.SourceName}
+	start := time.Now()
+	in := interp.NewInterpreter(&out)
+	v, err := r.call(in, suite, test.Name)
+	res.Duration = time.Since(start)
+	res.Output = out.String()
+	var e *interp.Exception
+	switch {
+	case errors.As(err, &e):
+		res.Failure = fmt.Sprintf("%s (code %d): %s", interp.CodeName(e.Code), e.Code, e.Message)
+		res.FailurePos = e.Pos
+	case err != nil:
+		res.Failure = err.Error()
+		res.FailurePos = test.Pos
+	case bool(value.ToLogic(v)):
+		res.Passed = true
+	default:
+		res.Failure = fmt.Sprintf("Test returned %q instead of logic true value", value.ToString(v))
+		res.FailurePos = test.Pos
+	}
+	return res
+}
+
+func (r *Runner) call(in *interp.Interpreter, suite *Suite, name string) (value.Value, error) {
+	if err := in.Load(suite.program); err != nil {
+		return nil, err
+	}
+	return in.Call(name)
+}
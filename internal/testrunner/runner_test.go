@@ -0,0 +1,223 @@
+package testrunner
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Allexy/fishes/internal/ast"
+)
+
+func _discover(t *testing.T, s string) *Suite {
+	suite, err := Discover(strings.NewReader(s), "string")
+	if err != nil {
+		t.Fatalf("Discovery failed with err: %v", err)
+	}
+	return suite
+}
+
+func TestDiscover(t *testing.T) {
+	source := `
+##
+# Not a description
+#
+func testPlain() { = true; }
+
+#@"first line
+#  second line"
+func testDescribed() { = true; }
+
+func helper() { = true; }
+
+#@"decorated"
+@helper
+func testDecorated() { = true; }
+
+#@"first desc"
+# helper note
+func testNoted() { = true; }
+`
+	suite := _discover(t, source)
+	expected := []Test{
+		{Name: "testPlain", Description: ""},
+		{Name: "testDescribed", Description: "first line second line"},
+		{Name: "testDecorated", Description: "decorated"},
+		{Name: "testNoted", Description: "first desc"},
+	}
+	if len(suite.Tests) != len(expected) {
+		t.Fatalf("Expected %d tests but got %d", len(expected), len(suite.Tests))
+	}
+	for i, e := range expected {
+		actual := suite.Tests[i]
+		if actual.Name != e.Name || actual.Description != e.Description {
+			t.Errorf("Expected test %s %q but got %s %q", e.Name, e.Description, actual.Name, actual.Description)
+		}
+	}
+	if pos := suite.Tests[1].Pos.String(); pos != "string:9:6" {
+		t.Errorf("Unexpected test position %s", pos)
+	}
+}
+
+func TestRunIsolated(t *testing.T) {
+	source := `
+func counter() { = 1; }
+func testFirst() { print("first"); = true; }
+func testSecond() {
+    assertEquals(1,
+        2);
+}
+func testFalse() { = 0; }
+$x = 1 / 0;
+`
+	results := NewRunner(nil).Run(_discover(t, source))
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results but got %d", len(results))
+	}
+	if !results[0].Passed || results[0].Output != "first\n" {
+		t.Errorf("Unexpected result of first test %+v", results[0])
+	}
+	r := results[1]
+	if r.Passed || r.FailurePos.String() != "string:5:5" ||
+		r.Failure != "AssertionError (code 4): java.lang.AssertionError: Expected first argument equals to second: v1 = 1 v2 = 2" {
+		t.Errorf("Unexpected result of second test %+v", r)
+	}
+	r = results[2]
+	if r.Passed || r.FailurePos.String() != "string:8:6" {
+		t.Errorf("Unexpected result of third test %+v", r)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	suite := _discover(t, "func testA() { = true; } func testAB() { = true; } func testB() { = true; }")
+	results := NewRunner(regexp.MustCompile("^testA")).Run(suite)
+	if len(results) != 2 || results[0].Name != "testA" || results[1].Name != "testAB" {
+		t.Errorf("Unexpected filtered results %+v", results)
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	f, err := os.Open("../../self_test.fs")
+	if err != nil {
+		t.Fatalf("Failed to open self test: %v", err)
+	}
+	defer f.Close()
+	suite, err := Discover(f, "self_test.fs")
+	if err != nil {
+		t.Fatalf("Discovery failed with err: %v", err)
+	}
+	results := NewRunner(nil).Run(suite)
+	if len(results) != 106 {
+		t.Errorf("Expected 106 tests but got %d", len(results))
+	}
+	for _, r := range results {
+		if !r.Passed {
+			t.Errorf("Test %s failed: %v: %s", r.Name, r.FailurePos, r.Failure)
+		}
+		if r.Name == "test_3" && r.Description != "when left operand is string then right should be cast to string to" {
+			t.Errorf("Unexpected description of test_3 %q", r.Description)
+		}
+	}
+}
+
+// Results used to check reporters: one passed and one failed test
+func _results() []Result {
+	return []Result{
+		{
+			Test:       Test{Name: "testA", Description: "adds #1", Pos: ast.Position{SourceName: "x.fs", Line: 2, Col: 6}},
+			SourceName: "x.fs",
+			Passed:     true,
+			Duration:   1500 * time.Microsecond,
+		},
+		{
+			Test:       Test{Name: "testB", Pos: ast.Position{SourceName: "x.fs", Line: 5, Col: 6}},
+			SourceName: "x.fs",
+			Failure:    "AssertionError (code 4): <oops>",
+			FailurePos: ast.Position{SourceName: "x.fs", Line: 6, Col: 5},
+			Duration:   2 * time.Millisecond,
+			Output:     "a\n",
+		},
+	}
+}
+
+func _report(t *testing.T, format string) string {
+	var sb strings.Builder
+	r, err := NewReporter(format, &sb)
+	if err != nil {
+		t.Fatalf("Failed to create reporter: %v", err)
+	}
+	for _, res := range _results() {
+		if err := r.Report(res); err != nil {
+			t.Fatalf("Report failed with err: %v", err)
+		}
+	}
+	if err := r.Finish(); err != nil {
+		t.Fatalf("Finish failed with err: %v", err)
+	}
+	return sb.String()
+}
+
+func TestTextReporter(t *testing.T) {
+	expected := "[+] testA() (0.002s): adds #1\n" +
+		"[-] testB() (0.002s)\n" +
+		"    x.fs:6:5: AssertionError (code 4): <oops>\n" +
+		"    | a\n" +
+		"[i] Passed: 1, failed: 1\n"
+	if actual := _report(t, FormatText); actual != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, actual)
+	}
+}
+
+func TestTAPReporter(t *testing.T) {
+	expected := "TAP version 13\n" +
+		"ok 1 - testA: adds \\#1\n" +
+		"not ok 2 - testB\n" +
+		"  ---\n" +
+		"  message: \"AssertionError (code 4): <oops>\"\n" +
+		"  file: \"x.fs\"\n" +
+		"  line: 6\n" +
+		"  col: 5\n" +
+		"  duration_ms: 2.000\n" +
+		"  output: \"a\\n\"\n" +
+		"  ...\n" +
+		"1..2\n"
+	if actual := _report(t, FormatTAP); actual != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, actual)
+	}
+}
+
+func TestJSONReporter(t *testing.T) {
+	expected := `{"name":"testA","description":"adds #1","file":"x.fs","line":2,"passed":true,"duration":0.0015}` + "\n" +
+		`{"name":"testB","file":"x.fs","line":5,"passed":false,"duration":0.002,"failure":{"message":"AssertionError (code 4): <oops>","file":"x.fs","line":6,"col":5},"output":"a\n"}` + "\n"
+	if actual := _report(t, FormatJSON); actual != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, actual)
+	}
+}
+
+func TestJUnitReporter(t *testing.T) {
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="2" failures="1" time="0.004">
+  <testsuite name="x.fs" tests="2" failures="1" time="0.004">
+    <testcase name="testA" classname="x.fs" time="0.002" file="x.fs" line="2">
+      <properties>
+        <property name="description" value="adds #1"></property>
+      </properties>
+    </testcase>
+    <testcase name="testB" classname="x.fs" time="0.002" file="x.fs" line="5">
+      <failure message="AssertionError (code 4): &lt;oops&gt;">x.fs:6:5: AssertionError (code 4): &lt;oops&gt;</failure>
+      <system-out>a&#xA;</system-out>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	if actual := _report(t, FormatJUnit); actual != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, actual)
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	if _, err := NewReporter("html", &strings.Builder{}); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
@@ -0,0 +1,95 @@
+package testrunner
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/Allexy/fishes/internal/ast"
+	"github.com/Allexy/fishes/internal/interp"
+	"github.com/Allexy/fishes/internal/parser"
+	"github.com/Allexy/fishes/internal/tokenizer"
+)
+
+// Marker of comment which describes test function: #@"description"
+const DescriptionMarker = "#@"
+
+// Test function found in source
+type Test struct {
+	Name        string
+	Description string // text of #@ comment, empty if there is none
+	Pos         ast.Position
+}
+
+// Tests of single source file
+type Suite struct {
+	SourceName string
+	Tests      []Test
+	program    *ast.Program
+}
+
+// Parses source and finds its test functions
+func Discover(r io.Reader, sourceName string) (*Suite, error) {
+	source, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	tw, err := tokenizer.NewTokenizer(bytes.NewReader(source), sourceName).Tokenize()
+	if err != nil {
+		return nil, err
+	}
+	program, err := parser.NewParser(tw).Parse()
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(source), "\n")
+	suite := &Suite{SourceName: sourceName, program: program}
+	for _, decl := range program.Funcs() {
+		if !strings.HasPrefix(decl.Name.Name, interp.TestPrefix) {
+			continue
+		}
+		suite.Tests = append(suite.Tests, Test{
+			Name:        decl.Name.Name,
+			Description: description(lines, int(decl.Pos().Line)),
+			Pos:         decl.Name.Pos(),
+		})
+	}
+	return suite, nil
+}
+
+// Finds #@ comment in the block of comment lines right above declaration line and returns its text.
+// Description may continue on next comment lines until closing quote
+func description(lines []string, declLine int) string {
+	first := declLine - 1
+	for first > 0 && strings.HasPrefix(strings.TrimSpace(lines[first-1]), "#") {
+		first--
+	}
+	for i := first; i < declLine-1; i++ {
+		line := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(line, DescriptionMarker) {
+			continue
+		}
+		text := strings.TrimSpace(strings.TrimPrefix(line, DescriptionMarker))
+		if !strings.HasPrefix(text, "\"") {
+			return text
+		}
+		// collect lines until closing quote, ordinary comments may follow it
+		text = text[1:]
+		var parts []string
+		for j := i + 1; ; j++ {
+			end := strings.Index(text, "\"")
+			if end >= 0 {
+				text = text[:end]
+			}
+			if text = strings.TrimSpace(text); text != "" {
+				parts = append(parts, text)
+			}
+			if end >= 0 || j >= declLine-1 {
+				break
+			}
+			text = strings.TrimLeft(strings.TrimSpace(lines[j]), "#")
+		}
+		return strings.Join(parts, " ")
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package testrunner
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Reporter producing Test Anything Protocol version 13, plan is written after the last test
+type TAPReporter struct {
+	w     io.Writer
+	count int
+}
+
+func NewTAPReporter(w io.Writer) *TAPReporter {
+	return &TAPReporter{w: w}
+}
+
+func (r *TAPReporter) Report(res Result) error {
+	var sb strings.Builder
+	if r.count == 0 {
+		sb.WriteString("TAP version 13\n")
+	}
+	r.count++
+	status := "ok"
+	if !res.Passed {
+		status = "not ok"
+	}
+	// "#" starts directive in TAP, so it must not appear in test description
+	fmt.Fprintf(&sb, "%s %d - %s", status, r.count, strings.ReplaceAll(res.Name, "#", "\\#"))
+	if res.Description != "" {
+		fmt.Fprintf(&sb, ": %s", strings.ReplaceAll(res.Description, "#", "\\#"))
+	}
+	sb.WriteByte('\n')
+	if !res.Passed {
+		sb.WriteString("  ---\n")
+		fmt.Fprintf(&sb, "  message: %s\n", strconv.Quote(res.Failure))
+		fmt.Fprintf(&sb, "  file: %s\n", strconv.Quote(res.FailurePos.SourceName))
+		fmt.Fprintf(&sb, "  line: %d\n", res.FailurePos.Line)
+		fmt.Fprintf(&sb, "  col: %d\n", res.FailurePos.Col)
+		fmt.Fprintf(&sb, "  duration_ms: %.3f\n", float64(res.Duration.Microseconds())/1000)
+		if res.Output != "" {
+			fmt.Fprintf(&sb, "  output: %s\n", strconv.Quote(res.Output))
+		}
+		sb.WriteString("  ...\n")
+	}
+	_, err := io.WriteString(r.w, sb.String())
+	return err
+}
+
+func (r *TAPReporter) Finish() error {
+	var err error
+	if r.count == 0 {
+		_, err = io.WriteString(r.w, "TAP version 13\n1..0\n")
+	} else {
+		_, err = fmt.Fprintf(r.w, "1..%d\n", r.count)
+	}
+	return err
+}
@@ -21,3 +21,19 @@ func (te TokenizerError) Error() string {
 func (te TokenizerError) Cause() error {
 	return te.cause
 }
+
+func (te TokenizerError) FileName() string {
+	return te.fileName
+}
+
+func (te TokenizerError) Message() string {
+	return te.message
+}
+
+func (te TokenizerError) Line() uint32 {
+	return te.line
+}
+
+func (te TokenizerError) Col() uint32 {
+	return te.col
+}
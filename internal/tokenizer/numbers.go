@@ -0,0 +1,144 @@
+package tokenizer
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Digit separator of numerical literals: 1_000_000
+const digitSeparator = '_'
+
+// Prefixes of integer literals in other bases
+var numberBases = []struct {
+	prefix string
+	base   int
+	name   string
+}{
+	{"0x", 16, "hexadecimal"},
+	{"0o", 8, "octal"},
+	{"0b", 2, "binary"},
+}
+
+// Returns canonical text of numerical literal: decimal digits without separators,
+// integer literals of other bases are converted to decimal, exponent is written as e[-]N.
+// Returns error message if literal is malformed
+func normalizeNumber(text string) (string, string) {
+	lower := strings.ToLower(text)
+	for _, b := range numberBases {
+		if strings.HasPrefix(lower, b.prefix) {
+			return normalizeBasedNumber(text, text[len(b.prefix):], b.base, b.name)
+		}
+	}
+	mantissa, exponent, hasExponent := text, "", false
+	if i := strings.IndexAny(text, "eE"); i >= 0 {
+		mantissa, exponent, hasExponent = text[:i], text[i+1:], true
+	}
+	intPart, fracPart, hasPoint := strings.Cut(mantissa, ".")
+	if msg := checkDigits(text, intPart, 10, true); msg != "" {
+		return "", msg
+	}
+	if msg := checkDigits(text, fracPart, 10, true); msg != "" {
+		return "", msg
+	}
+	intPart = strings.TrimLeft(removeSeparators(intPart), "0")
+	if intPart == "" {
+		intPart = "0"
+	}
+	canonical := intPart
+	if hasPoint {
+		fracPart = removeSeparators(fracPart)
+		if fracPart == "" {
+			fracPart = "0"
+		}
+		canonical += "." + fracPart
+	}
+	if !hasExponent {
+		return canonical, ""
+	}
+	sign := ""
+	if exponent != "" && (exponent[0] == '+' || exponent[0] == '-') {
+		if exponent[0] == '-' {
+			sign = "-"
+		}
+		exponent = exponent[1:]
+	}
+	if exponent == "" {
+		return "", fmt.Sprintf("Exponent has no digits in numerical literal %q", text)
+	}
+	if msg := checkDigits(text, exponent, 10, false); msg != "" {
+		return "", msg
+	}
+	exponent = strings.TrimLeft(removeSeparators(exponent), "0")
+	if exponent == "" {
+		exponent = "0"
+	}
+	return canonical + "e" + sign + exponent, ""
+}
+
+// Converts integer literal with base prefix to decimal text
+func normalizeBasedNumber(text, digits string, base int, name string) (string, string) {
+	if strings.ContainsRune(digits, '.') {
+		return "", fmt.Sprintf("Fraction is not allowed in %s literal %q", name, text)
+	}
+	// separator is allowed right after prefix: 0x_FF
+	digits = strings.TrimPrefix(digits, string(digitSeparator))
+	if digits == "" {
+		return "", fmt.Sprintf("No digits in %s literal %q", name, text)
+	}
+	if msg := checkDigits(text, digits, base, false); msg != "" {
+		return "", msg
+	}
+	digits = removeSeparators(digits)
+	n, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		return "", fmt.Sprintf("Invalid %s literal %q", name, text)
+	}
+	return n.String(), ""
+}
+
+// Checks digits of literal part, separators are allowed only between digits.
+// Empty part is valid if allowEmpty is set
+func checkDigits(text, part string, base int, allowEmpty bool) string {
+	if part == "" {
+		if allowEmpty {
+			return ""
+		}
+		return fmt.Sprintf("Missing digits in numerical literal %q", text)
+	}
+	prevDigit := false
+	for _, r := range part {
+		if r == digitSeparator {
+			if !prevDigit {
+				return fmt.Sprintf("Digit separator must be placed between digits in numerical literal %q", text)
+			}
+			prevDigit = false
+			continue
+		}
+		if digitValue(r) >= base {
+			return fmt.Sprintf("Invalid digit %q in numerical literal %q", r, text)
+		}
+		prevDigit = true
+	}
+	if !prevDigit {
+		return fmt.Sprintf("Digit separator must be placed between digits in numerical literal %q", text)
+	}
+	return ""
+}
+
+// Returns value of digit of any base up to 36, or 36 if rune is not a digit
+func digitValue(r rune) int {
+	switch {
+	case r >= '0' && r <= '9':
+		return int(r - '0')
+	case r >= 'a' && r <= 'z':
+		return int(r-'a') + 10
+	case r >= 'A' && r <= 'Z':
+		return int(r-'A') + 10
+	}
+	return 36
+}
+
+func removeSeparators(s string) string {
+	return strings.ReplaceAll(s, string(digitSeparator), "")
+}
@@ -0,0 +1,79 @@
+package tokenizer
+
+import "github.com/Allexy/fishes/internal/lang"
+
+// Comment markers, these are read as operators until they are complete
+const (
+	commentMarker             = "//"
+	multilineCommentMarker    = "/*"
+	multilineCommentEndMarker = "*/" // outside of comment it is always a mistake
+)
+
+// Prefix tree of operators, lets tokenizer take the longest operator
+type operatorTrie struct {
+	children map[rune]*operatorTrie
+	complete bool // runes from root to this node form an operator
+}
+
+// Operators of the language table, arrow and comment markers
+var operators = newOperatorTrie(operatorTexts()...)
+
+func operatorTexts() []string {
+	texts := make([]string, 0, len(lang.Operators)+4)
+	for _, o := range lang.Operators {
+		texts = append(texts, o.Text)
+	}
+	return append(texts, lang.OpArrow, commentMarker, multilineCommentMarker, multilineCommentEndMarker)
+}
+
+func newOperatorTrie(texts ...string) *operatorTrie {
+	root := &operatorTrie{}
+	for _, text := range texts {
+		node := root
+		for _, r := range text {
+			child, ok := node.children[r]
+			if !ok {
+				if node.children == nil {
+					node.children = make(map[rune]*operatorTrie)
+				}
+				child = &operatorTrie{}
+				node.children[r] = child
+			}
+			node = child
+		}
+		node.complete = true
+	}
+	return root
+}
+
+// Returns node reached by runes, nil if no operator begins with them
+func (t *operatorTrie) find(runes []rune) *operatorTrie {
+	node := t
+	for _, r := range runes {
+		if node = node.children[r]; node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// Returns true if some operator begins with runes followed by r
+func (t *operatorTrie) continues(runes []rune, r rune) bool {
+	node := t.find(runes)
+	return node != nil && node.children[r] != nil
+}
+
+// Returns number of runes of the longest operator which is prefix of runes, 0 if there is no such operator
+func (t *operatorTrie) longest(runes []rune) int {
+	n := 0
+	node := t
+	for i, r := range runes {
+		if node = node.children[r]; node == nil {
+			break
+		}
+		if node.complete {
+			n = i + 1
+		}
+	}
+	return n
+}
@@ -1,8 +1,8 @@
 package tokenizer
 
 import (
-	"errors"
 	"fmt"
+	"math/big"
 	"strconv"
 
 	"github.com/Allexy/fishes/internal/lang"
@@ -10,10 +10,6 @@ import (
 
 func optimizeAndValidate(tw TokenWalker) (TokenWalker, error) {
 
-	if tw.Size() < 3 {
-		return nil, errors.New("too few tokens in walker")
-	}
-
 	optimized := make([]Token, 0, 1024)
 
 	for tw.Next() {
@@ -22,15 +18,17 @@ func optimizeAndValidate(tw TokenWalker) (TokenWalker, error) {
 		next := tw.Get(1)
 		switch token.Token {
 		case TokenDefault:
-			return nil, NewTokenizerError(token.SourceName, fmt.Sprintf("Invalid token %v", token), token.Line, token.Col, nil)
+			return nil, errorAt(tw.FileSet(), token, fmt.Sprintf("Invalid token %v", token))
 		case TokenNumber:
-			filterNumbersText(token)
-			if isInvalidNumber(token) {
-				return nil, NewTokenizerError(token.SourceName, fmt.Sprintf("Invalid numerical literal %q", token.Text), token.Line, token.Col, nil)
+			if err := filterNumbersText(tw.FileSet(), token); err != nil {
+				return nil, err
+			}
+			if err := parseNumber(tw.FileSet(), token); err != nil {
+				return nil, err
 			}
 		case TokenOperator:
-			if isInvalidOperator(token, previous, next) {
-				return nil, NewTokenizerError(token.SourceName, fmt.Sprintf("Invalid operator %q", token.Text), token.Line, token.Col, nil)
+			if err := validateOperator(tw.FileSet(), token, previous, next); err != nil {
+				return nil, err
 			}
 			switch token.Text {
 			case lang.OpArrow:
@@ -40,8 +38,10 @@ func optimizeAndValidate(tw TokenWalker) (TokenWalker, error) {
 			case lang.OpPlus, lang.OpMinus:
 				// need to check if next token is numerical literal, it may be negative number
 				if tw.Match(TokenOperator, TokenNumber) {
-					if isNegativeNumberDetected(previous) {
-						filterNumbersText(next)
+					if isNegativeNumberDetected(previous, tw.Get(-2)) {
+						if err := filterNumbersText(tw.FileSet(), next); err != nil {
+							return nil, err
+						}
 						var newText string
 						if token.Text == lang.OpPlus {
 							newText = next.Text
@@ -49,14 +49,15 @@ func optimizeAndValidate(tw TokenWalker) (TokenWalker, error) {
 							newText = token.Text + next.Text
 						}
 						replacement := Token{
-							Token:      TokenNumber,
-							Text:       newText,
-							SourceName: token.SourceName,
-							Line:       token.Line,
-							Col:        token.Col,
+							Token:   TokenNumber,
+							Text:    newText,
+							Pos:     token.Pos,
+							End:     next.End,
+							Raw:     token.Raw + next.Source(),
+							Leading: token.Leading,
 						}
-						if isInvalidNumber(&replacement) {
-							return nil, NewTokenizerError(token.SourceName, fmt.Sprintf("Invalid numerical literal %q", token.Text), token.Line, token.Col, nil)
+						if err := parseNumber(tw.FileSet(), &replacement); err != nil {
+							return nil, err
 						}
 						optimized = append(optimized, replacement)
 						tw.Move(2) // Step over operator and up comming number
@@ -69,7 +70,17 @@ func optimizeAndValidate(tw TokenWalker) (TokenWalker, error) {
 			switch token.Text {
 			case lang.KwTrue, lang.KwFalse:
 				token.Token = TokenLogic
+				token.Value = token.Text == lang.KwTrue
+			case lang.KwNull:
+				token.Token = TokenNull
+				token.Value = Null{}
+			default:
+				if lang.IsKeyword(token.Text) {
+					token.Token = TokenKeyword
+				}
 			}
+		case TokenString, TokenStringHead, TokenStringMiddle, TokenStringTail:
+			token.Value = token.Text
 		}
 		optimized = append(optimized, *token)
 		tw.Move(1)
@@ -77,53 +88,88 @@ func optimizeAndValidate(tw TokenWalker) (TokenWalker, error) {
 
 	tw.Clear()
 
-	return NewTokenWalker(optimized), nil
+	return NewTokenWalker(tw.FileSet(), optimized), nil
 }
 
-func filterNumbersText(t *Token) {
-	if t.Text[0] == '.' {
-		t.Text = "0" + t.Text
-	} else if t.Text[len(t.Text)-1] == '.' {
-		t.Text += "0"
+// Replaces text of numerical literal with its canonical form
+func filterNumbersText(fileSet *FileSet, t *Token) error {
+	text, msg := normalizeNumber(t.Text)
+	if msg != "" {
+		return errorAt(fileSet, t, msg)
 	}
+	t.Text = text
+	return nil
 }
 
-func isNegativeNumberDetected(previous *Token) bool {
+// Returns true if sign is not binary operator, it is so unless previous token ends operand:
+// beforePrevious is needed to recognize postfix increment or decrement
+func isNegativeNumberDetected(previous *Token, beforePrevious *Token) bool {
 	if previous == nil {
 		return true
 	}
 	switch previous.Token {
 	// means that current token is part of arithmetic expression
-	case TokenNumber, TokenVariable, TokenCloseParen:
+	case TokenNumber, TokenString, TokenStringTail, TokenLogic, TokenNull, TokenVariable, TokenCloseParen, TokenCloseBracket:
 		return false
+	case TokenOperator:
+		if previous.Text == lang.OpIncrement || previous.Text == lang.OpDecrement {
+			return !isVariable(beforePrevious)
+		}
 	}
 	return true
 }
 
-func isInvalidOperator(c *Token, p *Token, n *Token) bool {
-	switch c.Text {
-	case lang.OpArrow, lang.OpPlus, lang.OpMinus, lang.OpDivision, lang.OpMultiply, lang.OpModulo, lang.OpAssign, lang.OpEquals,
-		lang.OpNotEquals, lang.OpGreaterThan, lang.OpGreaterThanOrEquals, lang.OpLesserThan, lang.OpLesserThanOrEquals, lang.OpNot,
-		lang.OpAnd, lang.OpOr, lang.OpIncrement, lang.OpDecrement, lang.OpPlusAssign, lang.OpMinusAssign, lang.OpDivideAssign,
-		lang.OpMultiplyAssign, lang.OpModuloAssign:
-		return false
-	}
+// Checks that operator is known and stands in allowed context:
+// compound assignments must follow variable, increment and decrement must touch variable
+func validateOperator(fileSet *FileSet, c *Token, p *Token, n *Token) error {
+	var message string
 	switch c.Text {
 	case lang.OpPlusAssign, lang.OpMinusAssign, lang.OpDivideAssign, lang.OpMultiplyAssign, lang.OpModuloAssign:
-		// These kinds of operators must follow by variable
-		if p == nil || p.Token != TokenVariable {
-			return false
+		if !isVariable(p) {
+			message = fmt.Sprintf("Operator %q must follow variable", c.Text)
 		}
 	case lang.OpIncrement, lang.OpDecrement:
-		// This kinds stands before or after variable
-		if (p == nil || p.Token != TokenVariable) && (n == nil || n.Token != TokenVariable) {
-			return false
+		if !isVariable(p) && !isVariable(n) {
+			message = fmt.Sprintf("Operator %q must be placed before or after variable", c.Text)
+		}
+	default:
+		if !isKnownOperator(c.Text) {
+			message = fmt.Sprintf("Invalid operator %q", c.Text)
 		}
 	}
-	return true
+	if message != "" {
+		return errorAt(fileSet, c, message)
+	}
+	return nil
+}
+
+// Returns true for operators of the language table and arrow
+func isKnownOperator(text string) bool {
+	for _, fixity := range []lang.Fixity{lang.Infix, lang.Prefix, lang.Postfix} {
+		if _, ok := lang.LookupOperator(text, fixity); ok {
+			return true
+		}
+	}
+	return text == lang.OpArrow
+}
+
+func isVariable(t *Token) bool {
+	return t != nil && t.Token == TokenVariable
+}
+
+// Sets exact value of numerical literal, numbers out of float range are rejected
+func parseNumber(fileSet *FileSet, t *Token) error {
+	if _, err := strconv.ParseFloat(t.Text, 64); err == nil {
+		if r, ok := new(big.Rat).SetString(t.Text); ok {
+			t.Value = r
+			return nil
+		}
+	}
+	return errorAt(fileSet, t, fmt.Sprintf("Invalid numerical literal %q", t.Text))
 }
 
-func isInvalidNumber(c *Token) bool {
-	_, ok := strconv.ParseFloat(c.Text, 64)
-	return ok != nil
+// Creates error pointing at the token
+func errorAt(fileSet *FileSet, t *Token, message string) error {
+	p := fileSet.Position(t.Pos)
+	return NewTokenizerError(p.Filename, message, p.Line, p.Col, nil)
 }
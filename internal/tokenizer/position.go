@@ -0,0 +1,254 @@
+package tokenizer
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Compact position of rune in file set, it is base of file plus byte offset of rune in the file
+type Pos int
+
+// Zero value of Pos which means no position
+const NoPos Pos = 0
+
+// Way of counting columns, zero value counts one column per rune
+type ColumnMode struct {
+	UTF16    bool // count UTF-16 code units as editors using LSP do
+	TabWidth int  // if positive, tab moves column to the next tab stop as terminals show it
+}
+
+// Returns column following the rune which begins at col
+func (m ColumnMode) advance(col uint32, r rune) uint32 {
+	switch {
+	case r == '\t' && m.TabWidth > 0:
+		w := uint32(m.TabWidth)
+		return (col-1)/w*w + w + 1
+	case m.UTF16 && r > 0xFFFF:
+		return col + 2
+	}
+	return col + 1
+}
+
+// Human readable position, line and col start from 1 and col is counted according to mode,
+// new line rune is at col 0 of the line it begins
+type Position struct {
+	Filename string
+	Offset   int // byte offset
+	Line     uint32
+	Col      uint32
+	Mode     ColumnMode // how col is counted
+}
+
+// Converts col to another column mode, line is text of the line containing position
+func (p Position) ColumnIn(line string, mode ColumnMode) uint32 {
+	if p.Col == 0 || mode == p.Mode {
+		return p.Col
+	}
+	from, to := uint32(1), uint32(1)
+	for _, r := range line {
+		if from >= p.Col {
+			break
+		}
+		from = p.Mode.advance(from, r)
+		to = mode.advance(to, r)
+	}
+	return to
+}
+
+// Returns true if position is set
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Col)
+}
+
+// Set of source files, every file gets its own range of positions, so tokens keep only compact positions
+type FileSet struct {
+	mutex sync.Mutex
+	base  int // base of the next file
+	files []*File
+}
+
+func NewFileSet() *FileSet {
+	return &FileSet{base: 1}
+}
+
+// Adds file of given size in bytes and reserves its range of positions,
+// so several files may be tokenized into the same set at once
+func (s *FileSet) AddFile(name string, size int) *File {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	f := &File{name: name, base: s.base, size: size, last: -1}
+	s.files = append(s.files, f)
+	s.base += size + 1 // position after the last byte belongs to the file too
+	return f
+}
+
+// Returns file containing position, nil if there is no such file
+func (s *FileSet) File(p Pos) *File {
+	if s == nil || p == NoPos {
+		return nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	i := sort.Search(len(s.files), func(i int) bool { return s.files[i].base > int(p) }) - 1
+	if i < 0 {
+		return nil
+	}
+	if f := s.files[i]; int(p) <= f.base+f.size {
+		return f
+	}
+	return nil
+}
+
+// Resolves position to file name, line and col
+func (s *FileSet) Position(p Pos) Position {
+	return s.File(p).Position(p)
+}
+
+// Resolves position immediately after the rune preceding p
+func (s *FileSet) EndPosition(p Pos) Position {
+	return s.File(p).EndPosition(p)
+}
+
+// Returns byte offset of position in its file, 0 if there is no such file
+func (s *FileSet) Offset(p Pos) int {
+	f := s.File(p)
+	if f == nil {
+		return 0
+	}
+	return f.Offset(p)
+}
+
+// Source file, keeps offsets of new lines, tabs and multi-byte runes so lines and cols are computed on demand
+type File struct {
+	name    string
+	mode    ColumnMode
+	base    int
+	size    int
+	last    int           // offset of the last rune, -1 for empty file
+	done    bool          // whole file is read
+	lines   []int         // offsets of new line runes
+	special []specialRune // runes which are not counted as single column of single byte
+}
+
+// Rune occupying more than one byte or tab
+type specialRune struct {
+	offset int
+	size   int
+	r      rune
+}
+
+func (f *File) Name() string {
+	return f.name
+}
+
+func (f *File) Base() int {
+	return f.base
+}
+
+// Returns size of file in bytes
+func (f *File) Size() int {
+	return f.size
+}
+
+// Returns number of lines read so far
+func (f *File) LineCount() int {
+	return len(f.lines) + 1
+}
+
+// Returns position of byte offset
+func (f *File) Pos(offset int) Pos {
+	return Pos(f.base + offset)
+}
+
+// Returns byte offset of position
+func (f *File) Offset(p Pos) int {
+	return int(p) - f.base
+}
+
+// Returns way of counting columns
+func (f *File) ColumnMode() ColumnMode {
+	return f.mode
+}
+
+// Sets way of counting columns
+func (f *File) SetColumnMode(mode ColumnMode) {
+	f.mode = mode
+}
+
+// Records rune, only new lines, tabs and multi-byte runes are kept
+func (f *File) addRune(offset int, r rune, size int) {
+	if r == '\n' {
+		f.lines = append(f.lines, offset)
+	} else if size > 1 || r == '\t' {
+		f.special = append(f.special, specialRune{offset, size, r})
+	}
+}
+
+// Marks file as read, last is offset of its last rune
+func (f *File) finish(last int) {
+	f.last = last
+	f.done = true
+}
+
+// Resolves position of rune, the end of file is reported at the last rune
+func (f *File) Position(p Pos) Position {
+	if f == nil {
+		return Position{}
+	}
+	if p == NoPos {
+		return Position{Filename: f.name}
+	}
+	offset := f.Offset(p)
+	if f.done && offset >= f.size {
+		if f.last < 0 {
+			return Position{Filename: f.name, Offset: offset, Line: 1, Mode: f.mode}
+		}
+		pos := f.position(f.last, false)
+		pos.Offset = offset
+		return pos
+	}
+	return f.position(offset, false)
+}
+
+// Resolves position immediately after the rune preceding p, so token ending before new line
+// ends on its own line
+func (f *File) EndPosition(p Pos) Position {
+	if f == nil {
+		return Position{}
+	}
+	if p == NoPos {
+		return Position{Filename: f.name}
+	}
+	return f.position(f.Offset(p), true)
+}
+
+func (f *File) position(offset int, end bool) Position {
+	// number of new lines before offset, new line at offset itself begins the line unless it is end
+	line := sort.SearchInts(f.lines, offset)
+	if !end && line < len(f.lines) && f.lines[line] == offset {
+		line++
+	}
+	lineStart := -1
+	if line > 0 {
+		lineStart = f.lines[line-1]
+	}
+	pos := Position{Filename: f.name, Offset: offset, Line: uint32(line + 1), Mode: f.mode}
+	if offset == lineStart {
+		return pos
+	}
+	// every rune between special ones takes single byte and single column
+	cursor, col := lineStart+1, uint32(1)
+	i := sort.Search(len(f.special), func(i int) bool { return f.special[i].offset >= cursor })
+	for ; i < len(f.special) && f.special[i].offset < offset; i++ {
+		s := f.special[i]
+		col = f.mode.advance(col+uint32(s.offset-cursor), s.r)
+		cursor = s.offset + s.size
+	}
+	pos.Col = col + uint32(offset-cursor)
+	return pos
+}
@@ -1,62 +1,137 @@
 package tokenizer
 
 import (
-	"bufio"
 	"fmt"
 	"io"
 	"unicode"
+	"unicode/utf8"
 )
 
 type Tokenizer struct {
 	sourceName string
 	reader     io.Reader
+	fileSet    *FileSet
+	file       *File
+	columns    ColumnMode
 
 	repeatCounter uint32
 
 	currentToken TokenType
-	currentLine  uint32
-	currentCol   uint32
 
-	tokenBegunLine uint32
-	tokenBegunCol  uint32
+	tokenEndOffset int
+	tokenEndKnown  bool // false if token ends with current rune
 
 	tokens []Token
 	buffer []rune
 
-	escapedString bool
+	escapedString  bool
+	rawString      bool            // string in back quotes, escape sequences are not processed
+	stringDollar   bool            // "$" is read in string, it begins interpolation if "{" follows
+	stringResumed  bool            // string continues after embedded expression
+	stringOffset   int             // offset of opening quote of string, it is kept while string continues
+	interpolations []interpolation // embedded expressions being read, innermost last
+	strictEscapes  bool
+	escapeDigits   int  // number of hexadecimal digits of escape sequence which are not read yet
+	escapeValue    rune // code point of escape sequence being read
+	escapeOffset   int  // offset of back slash of escape sequence being read
+
+	nestedComments bool
+	commentDepth   uint32 // number of nested comments opened inside of multiline comment
+	commentPrev    rune   // previous rune of multiline comment which is not part of "/*" or "*/"
+
+	trivia           bool
+	currentOffset    int // byte offset of current rune
+	currentSize      int // size of current rune in bytes
+	tokenBegunOffset int
+}
+
+// Marker of expression embedded into string: "text ${expression} text"
+const InterpolationMarker = "${"
+
+// Expression embedded into string
+type interpolation struct {
+	braces uint32 // number of open braces inside of expression
+	offset int    // offset of marker
+	quote  int    // offset of opening quote of string containing expression
+}
+
+// Option of tokenizer
+type Option func(tr *Tokenizer)
+
+// Keeps comments and white spaces: they are attached as leading trivia to the next significant token
+// (trivia at the end of source is attached to EOF token) and every token keeps its source text in Raw
+func WithTrivia() Option {
+	return func(tr *Tokenizer) {
+		tr.trivia = true
+	}
+}
+
+// Rejects unknown escape sequences in strings instead of keeping escaped rune as is
+func WithStrictEscapes() Option {
+	return func(tr *Tokenizer) {
+		tr.strictEscapes = true
+	}
+}
+
+// Places tokenized file into given file set, so positions of tokens of several files do not overlap
+func WithFileSet(fileSet *FileSet) Option {
+	return func(tr *Tokenizer) {
+		tr.fileSet = fileSet
+	}
+}
+
+// Sets way of counting columns of positions, e.g. UTF-16 code units for editors or expanded tabs for terminals
+func WithColumns(mode ColumnMode) Option {
+	return func(tr *Tokenizer) {
+		tr.columns = mode
+	}
 }
 
-func NewTokenizer(reader io.Reader, sourceName string) *Tokenizer {
+// Allows nested multiline comments: /* /* */ */, so code containing comments can be commented out
+func WithNestedComments() Option {
+	return func(tr *Tokenizer) {
+		tr.nestedComments = true
+	}
+}
+
+func NewTokenizer(reader io.Reader, sourceName string, options ...Option) *Tokenizer {
 	tr := &Tokenizer{
-		sourceName:     sourceName,
-		reader:         reader,
-		repeatCounter:  0,
-		currentToken:   TokenDefault,
-		currentLine:    0,
-		currentCol:     0,
-		tokenBegunLine: 0,
-		tokenBegunCol:  0,
-		tokens:         make([]Token, 0, 255),
-		buffer:         make([]rune, 0, 1024),
-		escapedString:  false,
+		sourceName:    sourceName,
+		reader:        reader,
+		repeatCounter: 0,
+		currentToken:  TokenDefault,
+		tokens:        make([]Token, 0, 255),
+		buffer:        make([]rune, 0, 1024),
+		escapedString: false,
+	}
+	for _, option := range options {
+		option(tr)
+	}
+	if tr.fileSet == nil {
+		tr.fileSet = NewFileSet()
 	}
 	return tr
 }
 
+// Returns file of tokenized source, it is nil until tokenization begins
+func (tr *Tokenizer) File() *File {
+	return tr.file
+}
+
 func (tr *Tokenizer) Tokenize() (TokenWalker, error) {
 	// does all stuff
-	bufReader := bufio.NewReader(tr.reader)
+	// whole source is read first, so range of positions of the file is reserved before tokens are created
+	source, err := io.ReadAll(tr.reader)
+	if err != nil {
+		return nil, NewTokenizerError(tr.sourceName, "Failed to read source: "+err.Error(), 0, 0, err)
+	}
+	tr.file = tr.fileSet.AddFile(tr.sourceName, len(source))
+	tr.file.SetColumnMode(tr.columns)
 	tr.createBOF()
-	for {
-		r, _, err := bufReader.ReadRune()
-		if err != nil {
-			if err == io.EOF {
-				tr.createEOF()
-				break
-			}
-			return nil, NewTokenizerError(tr.sourceName, "Failed to read source: "+err.Error(), tr.currentLine, tr.currentCol, err)
-		}
-		tr.countLinesAndCols(r)
+	for rest := source; len(rest) > 0; {
+		r, size := utf8.DecodeRune(rest)
+		rest = rest[size:]
+		tr.advance(r, size)
 		tr.doRepeat()
 		for tr.repeat() {
 			if err := tr.process(r); err != nil {
@@ -64,33 +139,78 @@ func (tr *Tokenizer) Tokenize() (TokenWalker, error) {
 			}
 		}
 	}
-	walker, err := optimizeAndValidate(NewTokenWalker(tr.tokens))
+	if err := tr.checkUnterminated(); err != nil {
+		return nil, err
+	}
+	tr.finish()
+	if tr.currentToken == TokenOperator {
+		if err := tr.createOperators(); err != nil {
+			return nil, err
+		}
+	}
+	tr.createEOF()
+	if tr.trivia {
+		tr.tokens = tr.attachTrivia(source)
+	}
+	walker, err := optimizeAndValidate(NewTokenWalker(tr.fileSet, tr.tokens))
 	if err != nil {
 		return nil, err
 	}
 	return walker, nil
 }
 
-// Appends token from current state
-//  and resets state
+// Appends token from current state and resets state
 func (tr *Tokenizer) createFromCurrent() {
 	// Skip comments and white spaces on this stage because these may contain more than 1 character more than 1 line
-	if tr.currentToken != TokenComment && tr.currentToken != TokenWhiteSpace && tr.currentToken != TokenMultilineComment {
+	if tr.trivia || !isTrivia(tr.currentToken) {
+		if !tr.tokenEndKnown {
+			tr.tokenEndOffset = tr.currentOffset + tr.currentSize
+		}
 		token := Token{
-			Token:      tr.currentToken,
-			Text:       string(tr.buffer),
-			SourceName: tr.sourceName,
-			Line:       tr.tokenBegunLine,
-			Col:        tr.tokenBegunCol,
+			Token: tr.currentToken,
+			Text:  string(tr.buffer),
+			Pos:   tr.file.Pos(tr.tokenBegunOffset),
+			End:   tr.file.Pos(tr.tokenEndOffset),
 		}
 		tr.tokens = append(tr.tokens, token)
 	}
 	tr.buffer = tr.buffer[:0]
-	tr.tokenBegunLine = 0
-	tr.tokenBegunCol = 0
+	tr.tokenEndOffset = 0
+	tr.tokenEndKnown = false
 	tr.currentToken = TokenDefault
 }
 
+// Appends token which ends before current rune, current rune is processed again
+func (tr *Tokenizer) createBeforeCurrent() {
+	tr.tokenEndOffset = tr.currentOffset
+	tr.tokenEndKnown = true
+	tr.createFromCurrent()
+}
+
+// Splits operator runes into the longest operators and appends them,
+// operators never contain new lines so each one begins right after previous one
+func (tr *Tokenizer) createOperators() error {
+	rest := append([]rune(nil), tr.buffer...)
+	for len(rest) > 0 {
+		n := operators.longest(rest)
+		if n == 0 {
+			return tr.errorAtOffset(tr.tokenBegunOffset, fmt.Sprintf("Invalid operator %q", string(rest)), nil)
+		}
+		offset := tr.tokenBegunOffset
+		tr.buffer = append(tr.buffer[:0], rest[:n]...)
+		size := len(string(tr.buffer))
+		tr.tokenEndOffset = offset + size
+		tr.tokenEndKnown = true
+		tr.createFromCurrent()
+		rest = rest[n:]
+		if len(rest) > 0 {
+			tr.currentToken = TokenOperator
+			tr.tokenBegunOffset = offset + size
+		}
+	}
+	return nil
+}
+
 func (tr *Tokenizer) createFromTypeAndRune(tt TokenType, r rune) {
 	tr.beginToken(tt)
 	tr.appendToBuffer(r)
@@ -102,20 +222,80 @@ func (tr *Tokenizer) appendToBuffer(r rune) {
 	tr.buffer = append(tr.buffer, r)
 }
 
+// Returns error if source ends inside of token which requires terminating sequence,
+// error points at the beginning of the token
+func (tr *Tokenizer) checkUnterminated() error {
+	var message string
+	offset := tr.tokenBegunOffset
+	if tr.currentToken == TokenString {
+		offset = tr.stringOffset
+	}
+	switch {
+	case tr.currentToken == TokenString && tr.rawString:
+		message = "Unterminated raw string"
+	case tr.currentToken == TokenString && tr.escapedString:
+		message = "Unterminated escape sequence in string"
+	case tr.currentToken == TokenString:
+		message = "Unterminated string"
+	case tr.currentToken == TokenMultilineComment:
+		message = "Unterminated multiline comment"
+	case len(tr.interpolations) > 0:
+		last := tr.interpolations[len(tr.interpolations)-1]
+		return tr.errorAtOffset(last.offset, "Unterminated expression in string", nil)
+	default:
+		return nil
+	}
+	return tr.errorAtOffset(offset, message, nil)
+}
+
+// Returns error pointing at rune beginning at byte offset
+func (tr *Tokenizer) errorAtOffset(offset int, message string, previous error) error {
+	pos := tr.file.Position(tr.file.Pos(offset))
+	return NewTokenizerError(tr.sourceName, message, pos.Line, pos.Col, previous)
+}
+
+// Sets source text of tokens and moves comments and white spaces to leading trivia of significant tokens.
+// Every token spans from its beginning to the beginning of the next one
+func (tr *Tokenizer) attachTrivia(source []byte) []Token {
+	significant := make([]Token, 0, len(tr.tokens))
+	var leading []Token
+	for i := range tr.tokens {
+		t := tr.tokens[i]
+		end := len(source)
+		if i+1 < len(tr.tokens) {
+			end = tr.file.Offset(tr.tokens[i+1].Pos)
+		}
+		if start := tr.file.Offset(t.Pos); t.Pos != NoPos && start < end {
+			t.Raw = string(source[start:end])
+		}
+		if isTrivia(t.Token) {
+			leading = append(leading, t)
+			continue
+		}
+		t.Leading = leading
+		leading = nil
+		significant = append(significant, t)
+	}
+	return significant
+}
+
+// Returns true for comments and white spaces
+func isTrivia(tt TokenType) bool {
+	return tt == TokenComment || tt == TokenWhiteSpace || tt == TokenMultilineComment
+}
+
 // Creates and appends token with type BOF
 func (tr *Tokenizer) createBOF() {
-	tr.beginToken(TokenBOF)
-	tr.createFromCurrent()
-	tr.currentLine = 1
+	tr.tokens = append(tr.tokens, Token{Token: TokenBOF})
 }
 
-// Creates and appends token with type EOF
+// Creates and appends token with type EOF, it has no width and its offset is the size of source
 func (tr *Tokenizer) createEOF() {
 	if tr.currentToken != TokenDefault {
-		tr.createFromCurrent()
+		tr.createBeforeCurrent()
 	}
 	tr.beginToken(TokenEOF)
-	tr.createFromCurrent()
+	tr.createBeforeCurrent()
 }
 
 // Processes single rune
@@ -124,80 +304,90 @@ func (tr *Tokenizer) process(r rune) error {
 	case TokenDefault:
 		return tr.handleDefaultState(r)
 	case TokenString:
-		if tr.escapedString {
-			switch r {
-			case 't':
-				tr.appendToBuffer('\t')
-			case 'b':
-				tr.appendToBuffer('\b')
-			case 'r':
-				tr.appendToBuffer('\r')
-			case 'n':
-				tr.appendToBuffer('\n')
-			case 'f':
-				tr.appendToBuffer('\f')
-			default:
-				tr.appendToBuffer(r)
-			}
-			tr.escapedString = false
-		} else {
-			switch r {
-			case '\\':
-				tr.escapedString = true
-			case '"':
+		if tr.rawString {
+			if r == '`' {
+				tr.rawString = false
 				tr.createFromCurrent()
-			default:
+			} else {
 				tr.appendToBuffer(r)
 			}
+			return nil
+		}
+		if tr.escapedString {
+			return tr.processEscape(r)
+		}
+		if tr.stringDollar {
+			tr.stringDollar = false
+			if r == '{' {
+				tr.beginInterpolation()
+				return nil
+			}
+			tr.appendToBuffer('$')
+		}
+		switch r {
+		case '\\':
+			tr.escapedString = true
+			tr.escapeOffset = tr.currentOffset
+		case '$':
+			tr.stringDollar = true
+		case '"':
+			if tr.stringResumed {
+				tr.currentToken = TokenStringTail
+			}
+			tr.createFromCurrent()
+		default:
+			tr.appendToBuffer(r)
 		}
 	case TokenNumber:
-		if unicode.IsDigit(r) {
+		if unicode.IsDigit(r) || unicode.IsLetter(r) || r == digitSeparator {
+			// letters are digits of other bases, exponent or mistakes reported by optimizer
+			tr.appendToBuffer(r)
+		} else if (r == '+' || r == '-') && tr.isExponentSign() {
 			tr.appendToBuffer(r)
 		} else if r == '.' {
 			for _, c := range tr.buffer {
 				if c == '.' {
-					return NewTokenizerError(tr.sourceName, "Unexpected symbol \".\"", tr.currentLine, tr.currentCol, nil)
+					return tr.errorAtOffset(tr.currentOffset, "Unexpected symbol \".\"", nil)
 				}
 			}
 			tr.appendToBuffer(r)
 		} else {
-			tr.createFromCurrent()
+			tr.createBeforeCurrent()
 			tr.doRepeat()
 		}
 	case TokenPoint:
 		if unicode.IsDigit(r) {
 			tr.currentToken = TokenNumber
 		} else {
-			tr.createFromCurrent()
+			tr.createBeforeCurrent()
 		}
 		tr.doRepeat()
 	case TokenOperator:
-		if len(tr.buffer) == 2 {
-			tr.createFromCurrent()
-			tr.doRepeat()
+		if operators.continues(tr.buffer, r) {
+			tr.appendToBuffer(r)
+			switch string(tr.buffer) {
+			case commentMarker:
+				tr.currentToken = TokenComment
+				tr.buffer = tr.buffer[:0]
+			case multilineCommentMarker:
+				tr.currentToken = TokenMultilineComment
+				tr.buffer = tr.buffer[:0]
+				tr.commentDepth = 0
+				tr.commentPrev = 0
+			case multilineCommentEndMarker:
+				return tr.errorAtOffset(tr.tokenBegunOffset, "Unexpected end of multiline comment", nil)
+			}
 		} else {
-			switch r {
-			case '>', '<', '=', '!', '+', '-', '/', '*', '&', '|', '%':
-				tr.appendToBuffer(r)
-				tTxt := string(tr.buffer)
-				switch tTxt {
-				case "//":
-					tr.currentToken = TokenComment
-					tr.buffer = tr.buffer[:0]
-				case "/*":
-					tr.currentToken = TokenMultilineComment
-					tr.buffer = tr.buffer[:0]
-				}
-			default:
-				tr.createFromCurrent()
-				tr.doRepeat()
+			if err := tr.createOperators(); err != nil {
+				return err
 			}
+			tr.doRepeat()
 		}
 	case TokenWord:
 		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
 			tr.appendToBuffer(r)
 		} else {
-			tr.createFromCurrent()
+			tr.createBeforeCurrent()
 			tr.doRepeat()
 		}
 	case TokenVariable:
@@ -205,10 +395,10 @@ func (tr *Tokenizer) process(r rune) error {
 			tr.appendToBuffer(r)
 		} else {
 			if len(tr.buffer) > 0 {
-				tr.createFromCurrent()
+				tr.createBeforeCurrent()
 				tr.doRepeat()
 			} else {
-				return NewTokenizerError(tr.sourceName, "Empty identifier", tr.currentLine, tr.currentCol, nil)
+				return tr.errorAtOffset(tr.currentOffset, "Empty identifier", nil)
 			}
 		}
 	case TokenComment:
@@ -217,23 +407,130 @@ func (tr *Tokenizer) process(r rune) error {
 			tr.createFromCurrent()
 		}
 	case TokenMultilineComment:
-		if r == '/' && len(tr.buffer) > 2 {
-			prevRune := tr.buffer[len(tr.buffer)-1]
-			if prevRune == '*' {
+		tr.appendToBuffer(r)
+		switch {
+		case tr.commentPrev == '*' && r == '/':
+			tr.commentPrev = 0
+			if tr.commentDepth == 0 {
+				// "*/" is not needed in token's text
+				tr.buffer = tr.buffer[:len(tr.buffer)-2]
 				tr.createFromCurrent()
+			} else {
+				tr.commentDepth--
 			}
+		case tr.nestedComments && tr.commentPrev == '/' && r == '*':
+			tr.commentPrev = 0
+			tr.commentDepth++
+		default:
+			tr.commentPrev = r
 		}
 	case TokenWhiteSpace:
 		if unicode.IsSpace(r) {
 			tr.appendToBuffer(r)
 		} else {
-			tr.createFromCurrent()
+			tr.createBeforeCurrent()
 			tr.doRepeat()
 		}
 	}
 	return nil
 }
 
+// Creates token of string part before "${" and starts tokenizing of embedded expression
+func (tr *Tokenizer) beginInterpolation() {
+	if tr.stringResumed {
+		tr.currentToken = TokenStringMiddle
+	} else {
+		tr.currentToken = TokenStringHead
+	}
+	tr.interpolations = append(tr.interpolations, interpolation{offset: tr.currentOffset - len("$"), quote: tr.stringOffset})
+	tr.createFromCurrent()
+}
+
+// Returns true if sign follows exponent mark of decimal number: 1e-9
+func (tr *Tokenizer) isExponentSign() bool {
+	last := tr.buffer[len(tr.buffer)-1]
+	if last != 'e' && last != 'E' {
+		return false
+	}
+	if len(tr.buffer) > 1 && tr.buffer[0] == '0' {
+		switch tr.buffer[1] {
+		case 'x', 'X', 'o', 'O', 'b', 'B':
+			return false
+		}
+	}
+	return true
+}
+
+// Processes rune following back slash in string:
+// \t \b \r \n \f \0 \" \\ \$ \xNN \uNNNN \UNNNNNNNN (N is hexadecimal digit)
+func (tr *Tokenizer) processEscape(r rune) error {
+	if tr.escapeDigits > 0 {
+		digit, ok := hexDigit(r)
+		if !ok {
+			return tr.errorAtOffset(tr.escapeOffset, fmt.Sprintf("Invalid hexadecimal digit %q in escape sequence", r), nil)
+		}
+		tr.escapeValue = tr.escapeValue<<4 | digit
+		tr.escapeDigits--
+		if tr.escapeDigits == 0 {
+			if !utf8.ValidRune(tr.escapeValue) {
+				return tr.errorAtOffset(tr.escapeOffset, fmt.Sprintf("Invalid code point %U in escape sequence", tr.escapeValue), nil)
+			}
+			tr.appendToBuffer(tr.escapeValue)
+			tr.escapedString = false
+		}
+		return nil
+	}
+	switch r {
+	case 't':
+		tr.appendToBuffer('\t')
+	case 'b':
+		tr.appendToBuffer('\b')
+	case 'r':
+		tr.appendToBuffer('\r')
+	case 'n':
+		tr.appendToBuffer('\n')
+	case 'f':
+		tr.appendToBuffer('\f')
+	case '0':
+		tr.appendToBuffer(0)
+	case 'x':
+		tr.beginHexEscape(2)
+		return nil
+	case 'u':
+		tr.beginHexEscape(4)
+		return nil
+	case 'U':
+		tr.beginHexEscape(8)
+		return nil
+	case '"', '\\', '$':
+		tr.appendToBuffer(r)
+	default:
+		if tr.strictEscapes {
+			return tr.errorAtOffset(tr.escapeOffset, fmt.Sprintf("Unknown escape sequence \"\\%c\"", r), nil)
+		}
+		tr.appendToBuffer(r)
+	}
+	tr.escapedString = false
+	return nil
+}
+
+func (tr *Tokenizer) beginHexEscape(digits int) {
+	tr.escapeDigits = digits
+	tr.escapeValue = 0
+}
+
+func hexDigit(r rune) (rune, bool) {
+	switch {
+	case r >= '0' && r <= '9':
+		return r - '0', true
+	case r >= 'a' && r <= 'f':
+		return r - 'a' + 10, true
+	case r >= 'A' && r <= 'F':
+		return r - 'A' + 10, true
+	}
+	return 0, false
+}
+
 // Starts new token or creates one form single rune
 func (tr *Tokenizer) handleDefaultState(r rune) error {
 
@@ -265,8 +562,22 @@ func (tr *Tokenizer) handleDefaultState(r rune) error {
 	case ']':
 		tr.createFromTypeAndRune(TokenCloseBracket, r)
 	case '{':
+		if n := len(tr.interpolations); n > 0 {
+			tr.interpolations[n-1].braces++
+		}
 		tr.createFromTypeAndRune(TokenOpenBrace, r)
 	case '}':
+		if n := len(tr.interpolations); n > 0 {
+			if tr.interpolations[n-1].braces == 0 {
+				// end of embedded expression, string continues
+				tr.stringOffset = tr.interpolations[n-1].quote
+				tr.interpolations = tr.interpolations[:n-1]
+				tr.beginToken(TokenString)
+				tr.stringResumed = true
+				return nil
+			}
+			tr.interpolations[n-1].braces--
+		}
 		tr.createFromTypeAndRune(TokenCloseBrace, r)
 	case ':':
 		tr.createFromTypeAndRune(TokenColon, r)
@@ -278,7 +589,13 @@ func (tr *Tokenizer) handleDefaultState(r rune) error {
 		tr.createFromTypeAndRune(TokenAt, r)
 	case '"':
 		tr.beginToken(TokenString)
+		tr.stringResumed = false
+		tr.stringOffset = tr.currentOffset
 		// leading and terminating quote marks must not be in string
+	case '`':
+		tr.beginToken(TokenString)
+		tr.rawString = true
+		tr.stringOffset = tr.currentOffset
 	case '$':
 		tr.beginToken(TokenVariable)
 		// $ is skipped
@@ -289,30 +606,39 @@ func (tr *Tokenizer) handleDefaultState(r rune) error {
 	case '#':
 		tr.beginToken(TokenComment)
 		// '#' sign is not needed in token's text
-	case '>', '<', '=', '!', '+', '-', '/', '*', '&', '|', '%':
-		tr.beginToken(TokenOperator)
-		tr.appendToBuffer(r)
 	default:
-		return NewTokenizerError(tr.sourceName, fmt.Sprintf("Unknown sumbol %q", r), tr.currentLine, tr.currentCol, nil)
+		if operators.continues(nil, r) {
+			tr.beginToken(TokenOperator)
+			tr.appendToBuffer(r)
+			return nil
+		}
+		return tr.errorAtOffset(tr.currentOffset, fmt.Sprintf("Unknown sumbol %q", r), nil)
 	}
 	return nil
 }
 
 // Initializes state
 func (tr *Tokenizer) beginToken(tt TokenType) {
-	tr.tokenBegunLine = tr.currentLine
-	tr.tokenBegunCol = tr.currentCol
+	tr.tokenBegunOffset = tr.currentOffset
 	tr.currentToken = tt
 }
 
-// Increments line number if current rune is \n otherwise increments col number
-func (tr *Tokenizer) countLinesAndCols(r rune) {
-	if r == '\n' {
-		tr.currentCol = 0
-		tr.currentLine += 1
-	} else {
-		tr.currentCol += 1
+// Moves offset to the next rune and records it in line table of file
+func (tr *Tokenizer) advance(r rune, size int) {
+	tr.currentOffset += tr.currentSize
+	tr.currentSize = size
+	tr.file.addRune(tr.currentOffset, r, size)
+}
+
+// Moves offset to the end of source and sets size of file
+func (tr *Tokenizer) finish() {
+	last := -1
+	if tr.currentSize > 0 {
+		last = tr.currentOffset
 	}
+	tr.currentOffset += tr.currentSize
+	tr.currentSize = 0
+	tr.file.finish(last)
 }
 
 // Returns true if current state requires to repeat iteration of rune processing
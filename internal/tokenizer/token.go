@@ -1,6 +1,10 @@
 package tokenizer
 
-import "fmt"
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
 
 type TokenType uint8
 
@@ -10,8 +14,9 @@ const (
 	TokenDefault                    // ...
 	TokenWord                       // abc
 	TokenNumber                     // 123.0
-	TokenString                     // "..."
+	TokenString                     // "..." or raw `...`
 	TokenLogic                      // true / false
+	TokenNull                       // null
 	TokenOperator                   // +-*/...
 	TokenOpenParen                  // (
 	TokenCloseParen                 // )
@@ -30,17 +35,97 @@ const (
 	TokenComment                    // #.... or //...
 	TokenMultilineComment           // /*...*/
 	TokenWhiteSpace                 // any white space
+	TokenStringHead                 // "...${ beginning of interpolated string
+	TokenStringMiddle               // }...${ part of interpolated string between embedded expressions
+	TokenStringTail                 // }..." end of interpolated string
+	TokenKeyword                    // func, if, while ...
 	TokenEOF
 )
 
 type Token struct {
-	Token      TokenType
-	Text       string
-	SourceName string
-	Line, Col  uint32
+	Token   TokenType
+	Text    string
+	Pos     Pos         // position of the first rune of token, it is resolved by file set
+	End     Pos         // position immediately after the token
+	Raw     string      // source text of token, set only in trivia mode
+	Leading []Token     // comments and white spaces before token, set only in trivia mode
+	Value   interface{} // typed value of literal: *big.Rat for numbers, string for strings, bool for logic, Null for null
+}
+
+// Marker stored as value of null literal
+type Null struct{}
+
+// Returns exact value of numerical literal, nil for other tokens
+func (t Token) Number() *big.Rat {
+	r, _ := t.Value.(*big.Rat)
+	return r
+}
+
+// Returns value of numerical literal as float, ok is false for other tokens
+func (t Token) Float() (f float64, ok bool) {
+	r := t.Number()
+	if r == nil {
+		return 0, false
+	}
+	f, _ = r.Float64()
+	return f, true
+}
+
+// Returns value of numerical literal as integer, ok is false for other tokens and for numbers out of int64
+func (t Token) Int() (i int64, ok bool) {
+	r := t.Number()
+	if r == nil || !r.IsInt() || !r.Num().IsInt64() {
+		return 0, false
+	}
+	return r.Num().Int64(), true
+}
+
+// Returns value of logical literal, false for other tokens
+func (t Token) Logic() bool {
+	b, _ := t.Value.(bool)
+	return b
+}
+
+// Returns true for null literal
+func (t Token) IsNull() bool {
+	_, ok := t.Value.(Null)
+	return ok
+}
+
+// Returns source text of leading trivia and token itself
+func (t Token) Source() string {
+	if len(t.Leading) == 0 {
+		return t.Raw
+	}
+	var sb strings.Builder
+	for _, l := range t.Leading {
+		sb.WriteString(l.Raw)
+	}
+	sb.WriteString(t.Raw)
+	return sb.String()
+}
+
+// Concatenates source text of tokens starting from current position of walker,
+// for tokens created in trivia mode result is exactly the tokenized source
+func Source(tw TokenWalker) string {
+	var sb strings.Builder
+	for i := 0; tw.CanMove(i); i++ {
+		sb.WriteString(tw.Get(i).Source())
+	}
+	return sb.String()
 }
 
 func (t Token) String() string {
+	return fmt.Sprintf("%s(%q)", t.name(), t.Text)
+}
+
+// Returns text of token with its line and col resolved by file set
+func (t Token) Describe(fileSet *FileSet) string {
+	p := fileSet.Position(t.Pos)
+	return fmt.Sprintf("%s(%q@%d:%d)", t.name(), t.Text, p.Line, p.Col)
+}
+
+func (t Token) name() string {
 	var name string
 	switch t.Token {
 	case TokenBOF:
@@ -55,6 +140,8 @@ func (t Token) String() string {
 		name = "NUMBER"
 	case TokenLogic:
 		name = "LOGIC"
+	case TokenNull:
+		name = "NULL"
 	case TokenOperator:
 		name = "OPERATOR"
 	case TokenOpenParen:
@@ -91,10 +178,18 @@ func (t Token) String() string {
 		name = "MULTILINE_COMMET"
 	case TokenWhiteSpace:
 		name = "WHITE_SPACE"
+	case TokenStringHead:
+		name = "STRING_HEAD"
+	case TokenStringMiddle:
+		name = "STRING_MIDDLE"
+	case TokenStringTail:
+		name = "STRING_TAIL"
+	case TokenKeyword:
+		name = "KEYWORD"
 	case TokenEOF:
 		name = "EOF"
 	default:
 		name = "UNKNOWN"
 	}
-	return fmt.Sprintf("%s(%q@%d:%d)", name, t.Text, t.Line, t.Col)
+	return name
 }
@@ -1,9 +1,14 @@
 package tokenizer
 
 import (
+	"fmt"
 	"io"
+	"os"
 	"strings"
+	"sync"
 	"testing"
+
+	"github.com/Allexy/fishes/internal/lang"
 )
 
 // Creates and returns string reader and source name
@@ -15,7 +20,7 @@ func _mk(s string) (io.Reader, string) {
 
 func TestTokenWalker(t *testing.T) {
 	tokens := []Token{{}, {}, {}}
-	tw := NewTokenWalker(tokens)
+	tw := NewTokenWalker(NewFileSet(), tokens)
 	if tw.Size() != 3 {
 		t.Errorf("Expected size 3 but got %d", tw.Size())
 	}
@@ -37,7 +42,7 @@ func TestTokenWalkerMatch(t *testing.T) {
 		{Token: TokenOpenBrace}, {Token: TokenCloseBrace}, {Token: TokenWord}, {Token: TokenCloseBrace},
 		{Token: TokenEOF},
 	}
-	tw := NewTokenWalker(tokens)
+	tw := NewTokenWalker(NewFileSet(), tokens)
 	tw.Move(1)
 	// Any tokens between prentices and braces are matched by TT_DEFAULT, walker takes into consederation nested
 	// opening and closing prentices
@@ -248,6 +253,20 @@ func TestNegativeNumberWithTerminatingPoint(t *testing.T) {
 	}
 }
 
+func TestNumberAfterString(t *testing.T) {
+	tw, err := NewTokenizer(_mk("\"xyz\" + 1")).Tokenize()
+	if err != nil {
+		t.Errorf("Tokenization failed with err: %v", err)
+	}
+	if tw.Size() != 5 {
+		t.Errorf("Expected 5 tokens in result got %d", tw.Size())
+	}
+	token := tw.Get(2)
+	if token.Token != TokenOperator {
+		t.Errorf("Expected token of type TT_OPERATOR but got %q", token)
+	}
+}
+
 // Testing string literals
 
 func TestString(t *testing.T) {
@@ -337,8 +356,148 @@ func TestLogicalFalse(t *testing.T) {
 	}
 }
 
+func TestKeywords(t *testing.T) {
+	tw, err := NewTokenizer(_mk("func if else while do for switch case try catch return throw funcs")).Tokenize()
+	if err != nil {
+		t.Fatalf("Tokenization failed with err: %v", err)
+	}
+	for i := 1; i <= 12; i++ {
+		if token := tw.Get(i); token.Token != TokenKeyword {
+			t.Errorf("Expected token of type KEYWORD but got %v", token)
+		}
+	}
+	if token := tw.Get(13); token.Token != TokenWord {
+		t.Errorf("Expected token of type WORD but got %v", token)
+	}
+}
+
+func TestNull(t *testing.T) {
+	tw, err := NewTokenizer(_mk("null - 1")).Tokenize()
+	if err != nil {
+		t.Fatalf("Tokenization failed with err: %v", err)
+	}
+	if tw.Size() != 5 {
+		t.Errorf("Expected 5 tokens in result got %d", tw.Size())
+	}
+	if token := tw.Get(1); token.Token != TokenNull || !token.IsNull() {
+		t.Errorf("Expected token of type NULL but got %v", token)
+	}
+	if token := tw.Get(2); token.Token != TokenOperator {
+		t.Errorf("Expected minus after null to be operator but got %v", token)
+	}
+}
+
 // Testing operators (I'll do this latter)
 
+func TestLongestOperator(t *testing.T) {
+	sources := map[string][]string{
+		"$a=-1":      {"a", "=", "-1"},
+		"!-$x":       {"!", "-", "x"},
+		"$a+=+$b":    {"a", "+=", "+", "b"},
+		"$a==!$b":    {"a", "==", "!", "b"},
+		"$a++-$b":    {"a", "++", "-", "b"},
+		"$a<=>$b":    {"a", "<=", ">", "b"},
+		"$a&&!$b||1": {"a", "&&", "!", "b", "||", "1"},
+		"$a+//c\n1":  {"a", "+", "1"},
+	}
+	for s, expected := range sources {
+		tw, err := NewTokenizer(_mk(s)).Tokenize()
+		if err != nil {
+			t.Errorf("Tokenization of %q failed with err: %v", s, err)
+			continue
+		}
+		var actual []string
+		for i := 1; i < tw.Size()-1; i++ {
+			actual = append(actual, tw.Get(i).Text)
+		}
+		if fmt.Sprint(actual) != fmt.Sprint(expected) {
+			t.Errorf("Expected %q for %q but got %q", expected, s, actual)
+		}
+	}
+	tw, err := NewTokenizer(_mk("$a--=1")).Tokenize()
+	if err != nil {
+		t.Fatalf("Tokenization failed with err: %v", err)
+	}
+	if token := tw.Get(3); tw.FileSet().Position(token.Pos).Col != 5 || token.Token != TokenAssignment {
+		t.Errorf("Expected assignment at col 5 but got %v", token)
+	}
+}
+
+func TestInvalidOperator(t *testing.T) {
+	sources := map[string]string{
+		"$a & $b;":  "Error in file string: Invalid operator \"&\"\nAt line 1; col: 4",
+		"$a |":      "Error in file string: Invalid operator \"|\"\nAt line 1; col: 4",
+		"$a &| $b;": "Error in file string: Invalid operator \"&\"\nAt line 1; col: 4",
+	}
+	for s, expected := range sources {
+		_, err := NewTokenizer(_mk(s)).Tokenize()
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q for %q but got %v", expected, s, err)
+		}
+	}
+}
+
+func TestOperatorContext(t *testing.T) {
+	for _, o := range lang.Operators {
+		var valid []string
+		var invalid map[string]string
+		switch o.Text {
+		case lang.OpPlusAssign, lang.OpMinusAssign, lang.OpDivideAssign, lang.OpMultiplyAssign, lang.OpModuloAssign:
+			valid = []string{"$a " + o.Text + " 1;"}
+			invalid = map[string]string{
+				"5 " + o.Text + " 1;":     "must follow variable\nAt line 1; col: 3",
+				"f() " + o.Text + " 1;":   "must follow variable\nAt line 1; col: 5",
+				o.Text + " $a;":           "must follow variable\nAt line 1; col: 1",
+				"\"a\" " + o.Text + " 1;": "must follow variable\nAt line 1; col: 5",
+			}
+		case lang.OpIncrement, lang.OpDecrement:
+			valid = []string{"$a " + o.Text + ";", o.Text + " $a;", "1 + $a" + o.Text + ";"}
+			invalid = map[string]string{
+				o.Text + " 3;":          "must be placed before or after variable\nAt line 1; col: 1",
+				"\"a\" " + o.Text + ";": "must be placed before or after variable\nAt line 1; col: 5",
+				"f() " + o.Text + ";":   "must be placed before or after variable\nAt line 1; col: 5",
+			}
+		default:
+			valid = []string{"1 " + o.Text + " 2;", "$a " + o.Text + " $b;", o.Text + " $a;"}
+		}
+		for _, s := range valid {
+			if _, err := NewTokenizer(_mk(s)).Tokenize(); err != nil {
+				t.Errorf("Tokenization of %q failed with err: %v", s, err)
+			}
+		}
+		for s, suffix := range invalid {
+			_, err := NewTokenizer(_mk(s)).Tokenize()
+			expected := fmt.Sprintf("Error in file string: Operator %q %s", o.Text, suffix)
+			if err == nil || err.Error() != expected {
+				t.Errorf("Expected error %q for %q but got %v", expected, s, err)
+			}
+		}
+	}
+	fileSet := NewFileSet()
+	file := fileSet.AddFile("string", 10)
+	file.finish(9)
+	unknown := &Token{Token: TokenOperator, Text: "&", Pos: file.Pos(1)}
+	if err := validateOperator(fileSet, unknown, nil, nil); err == nil || err.Error() != "Error in file string: Invalid operator \"&\"\nAt line 1; col: 2" {
+		t.Errorf("Expected unknown operator to be rejected but got %v", err)
+	}
+	if err := validateOperator(fileSet, &Token{Token: TokenOperator, Text: lang.OpArrow}, nil, nil); err != nil {
+		t.Errorf("Expected arrow to be valid but got %v", err)
+	}
+}
+
+func TestOperatorTrie(t *testing.T) {
+	trie := newOperatorTrie("*", "***", "*=", "**=")
+	cases := map[string]int{"*": 1, "**": 1, "***": 3, "**=": 3, "*=": 2, "=": 0, "": 0, "****": 3}
+	for s, expected := range cases {
+		if actual := trie.longest([]rune(s)); actual != expected {
+			t.Errorf("Expected longest operator of %q to have %d runes but got %d", s, expected, actual)
+		}
+	}
+	if !trie.continues([]rune("**"), '=') || trie.continues([]rune("*="), '=') || !trie.continues(nil, '*') {
+		t.Error("Unexpected continuation of operators")
+	}
+}
+
 // Testing syntax punctuation
 
 func TestOpenParen(t *testing.T) {
@@ -631,3 +790,514 @@ func TestVariableWithEverything(t *testing.T) {
 		t.Errorf("Expected token text is 'word00203word_s' but got %q", token)
 	}
 }
+
+// Testing trivia
+
+func TestTriviaReproducesSource(t *testing.T) {
+	self, err := os.ReadFile("../../self_test.fs")
+	if err != nil {
+		t.Fatalf("Failed to read self test: %v", err)
+	}
+	sources := []string{
+		string(self),
+		"  $a  ",
+		"# comment\n$a;",
+		"$a = - 1.; // negative\r\n$b = \"x\\\"y\\n\";\t# tail",
+		"func f() {\n    = .5 + -2;\n}\n\n",
+	}
+	for _, s := range sources {
+		tw, err := NewTokenizer(strings.NewReader(s), "string", WithTrivia()).Tokenize()
+		if err != nil {
+			t.Errorf("Tokenization of %q failed with err: %v", s, err)
+			continue
+		}
+		if actual := Source(tw); actual != s {
+			t.Errorf("Expected source %q but got %q", s, actual)
+		}
+	}
+}
+
+func TestTriviaIsLeading(t *testing.T) {
+	tw, err := NewTokenizer(strings.NewReader("# doc\n$a = -  1; # end"), "string", WithTrivia()).Tokenize()
+	if err != nil {
+		t.Fatalf("Tokenization failed with err: %v", err)
+	}
+	if tw.Size() != 6 {
+		t.Fatalf("Expected 6 tokens in result got %d", tw.Size())
+	}
+	variable := tw.Get(1)
+	if len(variable.Leading) != 1 || variable.Leading[0].Token != TokenComment || variable.Leading[0].Raw != "# doc\n" {
+		t.Errorf("Expected comment before variable but got %v", variable.Leading)
+	}
+	number := tw.Get(3)
+	if number.Token != TokenNumber || number.Text != "-1" || number.Raw != "-  1" || len(number.Leading) != 1 {
+		t.Errorf("Unexpected negative number %v %q %v", number, number.Raw, number.Leading)
+	}
+	eof := tw.Get(5)
+	if eof.Token != TokenEOF || len(eof.Leading) != 2 || eof.Leading[1].Raw != "# end" {
+		t.Errorf("Expected trailing comment before EOF but got %v", eof.Leading)
+	}
+	plain, err := NewTokenizer(_mk("# doc\n$a = -  1; # end")).Tokenize()
+	if err != nil {
+		t.Fatalf("Tokenization failed with err: %v", err)
+	}
+	if plain.Size() != 6 || plain.Get(1).Raw != "" || plain.Get(1).Leading != nil {
+		t.Error("Expected no trivia without option")
+	}
+}
+
+// Testing comments
+
+func TestMultilineComment(t *testing.T) {
+	tw, err := NewTokenizer(_mk("$a /* first\n line ** / */ $b /**/ $c /*/ */")).Tokenize()
+	if err != nil {
+		t.Fatalf("Tokenization failed with err: %v", err)
+	}
+	if tw.Size() != 5 {
+		t.Fatalf("Expected 5 tokens in result got %d", tw.Size())
+	}
+	b := tw.Get(2)
+	if b.Text != "b" || tw.FileSet().Position(b.Pos).Line != 2 || tw.FileSet().Position(b.Pos).Col != 15 {
+		t.Errorf("Expected variable b at 2:15 but got %v", b)
+	}
+	if c := tw.Get(3); c.Text != "c" {
+		t.Errorf("Expected variable c but got %v", c)
+	}
+}
+
+func TestMultilineCommentText(t *testing.T) {
+	tw, err := NewTokenizer(strings.NewReader("/* a * b */$a"), "string", WithTrivia()).Tokenize()
+	if err != nil {
+		t.Fatalf("Tokenization failed with err: %v", err)
+	}
+	comment := tw.Get(1).Leading[0]
+	if comment.Token != TokenMultilineComment || comment.Text != " a * b " || comment.Raw != "/* a * b */" {
+		t.Errorf("Unexpected comment %v %q", comment, comment.Raw)
+	}
+}
+
+func TestUnterminatedMultilineComment(t *testing.T) {
+	sources := map[string]string{
+		"$a;\n  /* comment\n\n":         "Error in file string: Unterminated multiline comment\nAt line 2; col: 3",
+		"/* /* */ */":                   "Error in file string: Unexpected end of multiline comment\nAt line 1; col: 10",
+		"$a; /* /* nested */\n$b;":      "Error in file string: Unterminated multiline comment\nAt line 1; col: 5",
+		"$a; /* /* nested */ */ /* /*/": "Error in file string: Unterminated multiline comment\nAt line 1; col: 24",
+	}
+	for s, expected := range sources {
+		var options []Option
+		if strings.Contains(s, "nested") {
+			options = append(options, WithNestedComments())
+		}
+		_, err := NewTokenizer(strings.NewReader(s), "string", options...).Tokenize()
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q for %q but got %v", expected, s, err)
+		}
+	}
+}
+
+func TestNestedMultilineComment(t *testing.T) {
+	tw, err := NewTokenizer(strings.NewReader("/* $a; /* inner */ $b; /**/ */ $c"), "string", WithNestedComments()).Tokenize()
+	if err != nil {
+		t.Fatalf("Tokenization failed with err: %v", err)
+	}
+	if tw.Size() != 3 || tw.Get(1).Text != "c" {
+		t.Errorf("Expected only variable c but got %d tokens", tw.Size())
+	}
+}
+
+func TestUnterminatedString(t *testing.T) {
+	sources := map[string]string{
+		"$a = \"text":                "Error in file string: Unterminated string\nAt line 1; col: 6",
+		"$a = 1;\n  \"first\nsecond": "Error in file string: Unterminated string\nAt line 2; col: 3",
+		"$a = \"text\\":              "Error in file string: Unterminated escape sequence in string\nAt line 1; col: 6",
+		"\"\\\"":                     "Error in file string: Unterminated string\nAt line 1; col: 1",
+		"print(\"a${1}":              "Error in file string: Unterminated string\nAt line 1; col: 7",
+		"\"a${\"b${1}\"} c":          "Error in file string: Unterminated string\nAt line 1; col: 1",
+	}
+	for s, expected := range sources {
+		_, err := NewTokenizer(_mk(s)).Tokenize()
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q for %q but got %v", expected, s, err)
+		}
+		if _, ok := err.(TokenizerError); !ok {
+			t.Errorf("Expected TokenizerError for %q but got %T", s, err)
+		}
+	}
+}
+
+func TestStringWithHexEscSeq(t *testing.T) {
+	sources := map[string]string{
+		`"\x41\x7a"`:             "Az",
+		`"\u042d\u0422\u041E"`:   "ЭТО",
+		`"\U0001F41F\U00000041"`: "🐟A",
+		`"a\0b"`:                 "a\x00b",
+		`"C:\\temp\"x\""`:        `C:\temp"x"`,
+		`"\q"`:                   "q",
+		`"\u00e9\u0301 \xe9"`:    "\u00e9\u0301 \u00e9",
+	}
+	for s, expected := range sources {
+		tw, err := NewTokenizer(_mk(s)).Tokenize()
+		if err != nil {
+			t.Errorf("Tokenization of %s failed with err: %v", s, err)
+			continue
+		}
+		if token := tw.Get(1); token.Token != TokenString || token.Text != expected {
+			t.Errorf("Expected string %q for %s but got %v", expected, s, token)
+		}
+	}
+}
+
+func TestInvalidEscSeq(t *testing.T) {
+	sources := map[string]string{
+		"$a = \"\\x4g\";":            "Error in file string: Invalid hexadecimal digit 'g' in escape sequence\nAt line 1; col: 7",
+		"$a = \"ok\\u12\";":          "Error in file string: Invalid hexadecimal digit '\"' in escape sequence\nAt line 1; col: 9",
+		"\"\\uD800\"":                "Error in file string: Invalid code point U+D800 in escape sequence\nAt line 1; col: 2",
+		"\"\\U00110000\"":            "Error in file string: Invalid code point U+110000 in escape sequence\nAt line 1; col: 2",
+		"\"\\u00":                    "Error in file string: Unterminated escape sequence in string\nAt line 1; col: 1",
+		"$p = \"C:\\temp\\new\\q\";": "Error in file string: Unknown escape sequence \"\\q\"\nAt line 1; col: 18",
+	}
+	for s, expected := range sources {
+		_, err := NewTokenizer(strings.NewReader(s), "string", WithStrictEscapes()).Tokenize()
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q for %q but got %v", expected, s, err)
+		}
+	}
+}
+
+func TestRawString(t *testing.T) {
+	tw, err := NewTokenizer(_mk("$q = `SELECT \"a\\n\"\n  FROM t`; $b")).Tokenize()
+	if err != nil {
+		t.Fatalf("Tokenization failed with err: %v", err)
+	}
+	token := tw.Get(3)
+	if token.Token != TokenString || token.Text != "SELECT \"a\\n\"\n  FROM t" {
+		t.Errorf("Expected raw string but got %v", token)
+	}
+	if tw.FileSet().Position(token.Pos).Line != 1 || tw.FileSet().Position(token.Pos).Col != 6 || tw.FileSet().EndPosition(token.End).Line != 2 || tw.FileSet().EndPosition(token.End).Col != 10 {
+		t.Errorf("Expected raw string at 1:6-2:10 but got %d:%d-%d:%d", tw.FileSet().Position(token.Pos).Line, tw.FileSet().Position(token.Pos).Col, tw.FileSet().EndPosition(token.End).Line, tw.FileSet().EndPosition(token.End).Col)
+	}
+	if b := tw.Get(5); b.Text != "b" || tw.FileSet().Position(b.Pos).Line != 2 || tw.FileSet().Position(b.Pos).Col != 12 {
+		t.Errorf("Expected variable b at 2:12 but got %v", b)
+	}
+}
+
+func TestStringEndPosition(t *testing.T) {
+	tw, err := NewTokenizer(_mk("\"a\nbc\" \"\"")).Tokenize()
+	if err != nil {
+		t.Fatalf("Tokenization failed with err: %v", err)
+	}
+	if s := tw.Get(1); tw.FileSet().EndPosition(s.End).Line != 2 || tw.FileSet().EndPosition(s.End).Col != 4 {
+		t.Errorf("Expected multi-line string to end at 2:4 but got %d:%d", tw.FileSet().EndPosition(s.End).Line, tw.FileSet().EndPosition(s.End).Col)
+	}
+	if s := tw.Get(2); tw.FileSet().Position(s.Pos).Line != 2 || tw.FileSet().Position(s.Pos).Col != 5 || tw.FileSet().EndPosition(s.End).Line != 2 || tw.FileSet().EndPosition(s.End).Col != 7 {
+		t.Errorf("Expected empty string at 2:5-2:7 but got %d:%d-%d:%d", tw.FileSet().Position(s.Pos).Line, tw.FileSet().Position(s.Pos).Col, tw.FileSet().EndPosition(s.End).Line, tw.FileSet().EndPosition(s.End).Col)
+	}
+}
+
+func TestUnterminatedRawString(t *testing.T) {
+	_, err := NewTokenizer(_mk("$a;\n $b = `text\n\n")).Tokenize()
+	expected := "Error in file string: Unterminated raw string\nAt line 2; col: 7"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q but got %v", expected, err)
+	}
+}
+
+// Testing interpolation
+
+func TestInterpolatedString(t *testing.T) {
+	tw, err := NewTokenizer(_mk("\"n = ${$n * 2}, ${ {} } $n \\${x}\" - 1")).Tokenize()
+	if err != nil {
+		t.Fatalf("Tokenization failed with err: %v", err)
+	}
+	expected := []string{
+		`BOF(""@0:0)`,
+		`STRING_HEAD("n = "@1:1)`,
+		`VARIABLE("n"@1:8)`,
+		`OPERATOR("*"@1:11)`,
+		`NUMBER("2"@1:13)`,
+		`STRING_MIDDLE(", "@1:14)`,
+		`O_BRACE("{"@1:20)`,
+		`C_BRACE("}"@1:21)`,
+		`STRING_TAIL(" $n ${x}"@1:23)`,
+		`OPERATOR("-"@1:35)`,
+		`NUMBER("1"@1:37)`,
+		`EOF(""@1:37)`,
+	}
+	if tw.Size() != len(expected) {
+		t.Fatalf("Expected %d tokens in result got %d", len(expected), tw.Size())
+	}
+	for i, e := range expected {
+		if actual := tw.Get(i).Describe(tw.FileSet()); actual != e {
+			t.Errorf("Expected token %s but got %s", e, actual)
+		}
+	}
+	if head := tw.Get(1); tw.FileSet().EndPosition(head.End).Line != 1 || tw.FileSet().EndPosition(head.End).Col != 8 {
+		t.Errorf("Expected string head to end at 1:8 but got %d:%d", tw.FileSet().EndPosition(head.End).Line, tw.FileSet().EndPosition(head.End).Col)
+	}
+}
+
+func TestNestedInterpolatedString(t *testing.T) {
+	s := "\"a${\"b${$c}\"}d\""
+	tw, err := NewTokenizer(strings.NewReader(s), "string", WithTrivia()).Tokenize()
+	if err != nil {
+		t.Fatalf("Tokenization failed with err: %v", err)
+	}
+	var types []TokenType
+	for i := 1; i < tw.Size()-1; i++ {
+		types = append(types, tw.Get(i).Token)
+	}
+	expected := []TokenType{TokenStringHead, TokenStringHead, TokenVariable, TokenStringTail, TokenStringTail}
+	if fmt.Sprint(types) != fmt.Sprint(expected) {
+		t.Errorf("Expected token types %v but got %v", expected, types)
+	}
+	if Source(tw) != s {
+		t.Errorf("Expected source %q but got %q", s, Source(tw))
+	}
+}
+
+func TestUnterminatedInterpolation(t *testing.T) {
+	sources := map[string]string{
+		"$a = \"x ${$b + \n 1;":   "Error in file string: Unterminated expression in string\nAt line 1; col: 9",
+		"$a = \"x ${$b} y":        "Error in file string: Unterminated string\nAt line 1; col: 6",
+		"$a = \"x ${ {$b} \"y\";": "Error in file string: Unterminated expression in string\nAt line 1; col: 9",
+	}
+	for s, expected := range sources {
+		_, err := NewTokenizer(_mk(s)).Tokenize()
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q for %q but got %v", expected, s, err)
+		}
+	}
+}
+
+func TestExtendedNumbers(t *testing.T) {
+	sources := map[string]string{
+		"0xFF":       "255",
+		"0X_ff_00":   "65280",
+		"0o17":       "15",
+		"0b1010":     "10",
+		"-0b1":       "-1",
+		"1e-9":       "1e-9",
+		"2.5E+03":    "2.5e3",
+		".5e1":       "0.5e1",
+		"5.e0":       "5.0e0",
+		"1_000_000":  "1000000",
+		"3.141_592":  "3.141592",
+		"007":        "7",
+		"00.5":       "0.5",
+		"1_0e1_0":    "10e10",
+		"-1_000.0_1": "-1000.01",
+		"0":          "0",
+		"0.0":        "0.0",
+	}
+	for s, expected := range sources {
+		tw, err := NewTokenizer(_mk(s)).Tokenize()
+		if err != nil {
+			t.Errorf("Tokenization of %q failed with err: %v", s, err)
+			continue
+		}
+		if token := tw.Get(1); token.Token != TokenNumber || token.Text != expected {
+			t.Errorf("Expected number %q for %q but got %v", expected, s, token)
+		}
+	}
+}
+
+func TestMalformedNumbers(t *testing.T) {
+	sources := map[string]string{
+		"$a = 0x;":    "No digits in hexadecimal literal \"0x\"",
+		"$a = 0xFG;":  "Invalid digit 'G' in numerical literal \"0xFG\"",
+		"$a = 0o8;":   "Invalid digit '8' in numerical literal \"0o8\"",
+		"$a = 0b102;": "Invalid digit '2' in numerical literal \"0b102\"",
+		"$a = 0x1.5;": "Fraction is not allowed in hexadecimal literal \"0x1.5\"",
+		"$a = 1e;":    "Exponent has no digits in numerical literal \"1e\"",
+		"$a = 1e+;":   "Exponent has no digits in numerical literal \"1e+\"",
+		"$a = 1__0;":  "Digit separator must be placed between digits in numerical literal \"1__0\"",
+		"$a = 10_;":   "Digit separator must be placed between digits in numerical literal \"10_\"",
+		"$a = 1_.5;":  "Digit separator must be placed between digits in numerical literal \"1_.5\"",
+		"$a = 12abc;": "Invalid digit 'a' in numerical literal \"12abc\"",
+		"$a = 0x_;":   "No digits in hexadecimal literal \"0x_\"",
+		"$a = 1e5.5;": "Invalid digit '.' in numerical literal \"1e5.5\"",
+	}
+	for s, message := range sources {
+		_, err := NewTokenizer(_mk(s)).Tokenize()
+		expected := "Error in file string: " + message + "\nAt line 1; col: 6"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q for %q but got %v", expected, s, err)
+		}
+	}
+}
+
+func TestLiteralValues(t *testing.T) {
+	tw, err := NewTokenizer(_mk("0x10; -2.5e1; 0.1 true false null \"a\\tb\" 1e30 abc")).Tokenize()
+	if err != nil {
+		t.Fatalf("Tokenization failed with err: %v", err)
+	}
+	numbers := map[int]string{1: "16", 3: "-25", 5: "1/10", 10: "1000000000000000000000000000000"}
+	for i, expected := range numbers {
+		if r := tw.Get(i).Number(); r == nil || r.RatString() != expected {
+			t.Errorf("Expected number %s at %d but got %v", expected, i, tw.Get(i))
+		}
+	}
+	if i, ok := tw.Get(1).Int(); !ok || i != 16 {
+		t.Errorf("Expected integer 16 but got %d", i)
+	}
+	if i, ok := tw.Get(3).Int(); !ok || i != -25 {
+		t.Errorf("Expected integer -25 but got %d", i)
+	}
+	if _, ok := tw.Get(5).Int(); ok {
+		t.Error("Expected 0.1 not to be integer")
+	}
+	if _, ok := tw.Get(10).Int(); ok {
+		t.Error("Expected 1e30 not to fit integer")
+	}
+	if f, ok := tw.Get(5).Float(); !ok || f != 0.1 {
+		t.Errorf("Expected float 0.1 but got %v", f)
+	}
+	if !tw.Get(6).Logic() || tw.Get(7).Logic() || tw.Get(7).Value != false {
+		t.Errorf("Unexpected logic values %v %v", tw.Get(6).Value, tw.Get(7).Value)
+	}
+	if !tw.Get(8).IsNull() || tw.Get(11).IsNull() {
+		t.Error("Expected only null literal to be null")
+	}
+	if tw.Get(9).Value != "a\tb" {
+		t.Errorf("Unexpected string value %q", tw.Get(9).Value)
+	}
+	if tw.Get(11).Value != nil || tw.Get(11).Number() != nil {
+		t.Errorf("Expected word to have no value but got %v", tw.Get(11).Value)
+	}
+	if _, err := NewTokenizer(_mk("$a = 1e400;")).Tokenize(); err == nil {
+		t.Error("Expected number out of range to be rejected")
+	}
+}
+
+func TestTokenSpans(t *testing.T) {
+	source := "$мир = -1.5e1 <= \"a\nb\";  # note\n/* c */ f(`x`) + 0xFF"
+	tw, err := NewTokenizer(strings.NewReader(source), "string", WithTrivia()).Tokenize()
+	if err != nil {
+		t.Fatalf("Tokenization failed with err: %v", err)
+	}
+	expected := []struct {
+		text                       string
+		line, col, endLine, endCol uint32
+	}{
+		{"", 0, 0, 0, 0},
+		{"$мир", 1, 1, 1, 5},
+		{"=", 1, 6, 1, 7},
+		{"-1.5e1", 1, 8, 1, 14},
+		{"<=", 1, 15, 1, 17},
+		{"\"a\nb\"", 1, 18, 2, 3},
+		{";", 2, 3, 2, 4},
+		{"f", 3, 9, 3, 10},
+		{"(", 3, 10, 3, 11},
+		{"`x`", 3, 11, 3, 14},
+		{")", 3, 14, 3, 15},
+		{"+", 3, 16, 3, 17},
+		{"0xFF", 3, 18, 3, 22},
+		{"", 3, 21, 3, 22}, // EOF is reported at the last rune
+	}
+	if tw.Size() != len(expected) {
+		t.Fatalf("Expected %d tokens but got %d", len(expected), tw.Size())
+	}
+	for i, e := range expected {
+		token := tw.Get(i)
+		if text := source[tw.FileSet().Offset(token.Pos):tw.FileSet().Offset(token.End)]; text != e.text {
+			t.Errorf("Expected source %q of token %v but got %q", e.text, token, text)
+		}
+		if tw.FileSet().Position(token.Pos).Line != e.line || tw.FileSet().Position(token.Pos).Col != e.col || tw.FileSet().EndPosition(token.End).Line != e.endLine || tw.FileSet().EndPosition(token.End).Col != e.endCol {
+			t.Errorf("Expected %q at %d:%d-%d:%d but got %v ending at %d:%d", e.text, e.line, e.col, e.endLine, e.endCol, token, tw.FileSet().EndPosition(token.End).Line, tw.FileSet().EndPosition(token.End).Col)
+		}
+	}
+	comment := tw.Get(7).Leading[1]
+	if source[tw.FileSet().Offset(comment.Pos):tw.FileSet().Offset(comment.End)] != "# note\n" || tw.FileSet().EndPosition(comment.End).Line != 3 || tw.FileSet().EndPosition(comment.End).Col != 1 {
+		t.Errorf("Unexpected span of comment %v: %d-%d", comment, tw.FileSet().Offset(comment.Pos), tw.FileSet().Offset(comment.End))
+	}
+	if eof := tw.Get(len(expected) - 1); tw.FileSet().Offset(eof.Pos) != len(source) || tw.FileSet().Offset(eof.End) != len(source) {
+		t.Errorf("Expected EOF at offset %d but got %d", len(source), tw.FileSet().Offset(eof.Pos))
+	}
+}
+
+func TestFileSet(t *testing.T) {
+	fileSet := NewFileSet()
+	sources := []string{"$a = 1;\n$ё = 2;", "\n\n  f();"}
+	walkers := make([]TokenWalker, len(sources))
+	errs := make([]error, len(sources))
+	// files are tokenized at once, each one reserves its own range of positions
+	var wg sync.WaitGroup
+	for i, s := range sources {
+		wg.Add(1)
+		go func(i int, s string) {
+			defer wg.Done()
+			walkers[i], errs[i] = NewTokenizer(strings.NewReader(s), fmt.Sprintf("file%d.fs", i), WithFileSet(fileSet)).Tokenize()
+		}(i, s)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("Tokenization failed with err: %v", err)
+		}
+	}
+	cases := []struct {
+		token    *Token
+		expected string
+	}{
+		{walkers[0].Get(1), "file0.fs:1:1"},
+		{walkers[0].Get(5), "file0.fs:2:1"},
+		{walkers[0].Get(6), "file0.fs:2:4"},
+		{walkers[0].Get(8), "file0.fs:2:7"},
+		{walkers[1].Get(1), "file1.fs:3:3"},
+		{walkers[1].Get(4), "file1.fs:3:6"},
+	}
+	for _, c := range cases {
+		if p := fileSet.Position(c.token.Pos); p.String() != c.expected {
+			t.Errorf("Expected %v at %s but got %s", c.token, c.expected, p)
+		}
+	}
+	first, second := fileSet.File(walkers[0].Get(1).Pos), fileSet.File(walkers[1].Get(1).Pos)
+	if first.Size() != len(sources[0]) || first.LineCount() != 2 || second.LineCount() != 3 {
+		t.Errorf("Unexpected size %d or line counts %d, %d", first.Size(), first.LineCount(), second.LineCount())
+	}
+	if fileSet.File(walkers[1].Get(2).Pos) != second || fileSet.File(NoPos) != nil || fileSet.File(Pos(fileSet.base+100)) != nil {
+		t.Error("Unexpected file found by position")
+	}
+	if s := walkers[1].Get(1).Describe(fileSet); s != "WORD(\"f\"@3:3)" {
+		t.Errorf("Unexpected token %s", s)
+	}
+	a, b := fileSet.AddFile("a", 10), fileSet.AddFile("b", 5)
+	if b.Base() <= a.Base()+a.Size() || fileSet.File(a.Pos(10)) != a || fileSet.File(b.Pos(0)) != b {
+		t.Errorf("Expected files not to overlap but got bases %d and %d", a.Base(), b.Base())
+	}
+}
+
+func TestColumnModes(t *testing.T) {
+	line := "\t\"😀\" + $a;"
+	modes := []struct {
+		mode     ColumnMode
+		expected []uint32 // cols of string, operator, variable
+	}{
+		{ColumnMode{}, []uint32{2, 6, 8}},
+		{ColumnMode{UTF16: true}, []uint32{2, 7, 9}},
+		{ColumnMode{TabWidth: 4}, []uint32{5, 9, 11}},
+		{ColumnMode{UTF16: true, TabWidth: 4}, []uint32{5, 10, 12}},
+	}
+	for _, m := range modes {
+		tw, err := NewTokenizer(strings.NewReader(line), "string", WithColumns(m.mode)).Tokenize()
+		if err != nil {
+			t.Fatalf("Tokenization failed with err: %v", err)
+		}
+		for i, col := range m.expected {
+			if p := tw.FileSet().Position(tw.Get(i + 1).Pos); p.Line != 1 || p.Col != col || p.Mode != m.mode {
+				t.Errorf("Expected %v at col %d in mode %+v but got %s", tw.Get(i+1), col, m.mode, p)
+			}
+		}
+		// converted position must be the same as position computed in other mode
+		p := tw.FileSet().Position(tw.Get(3).Pos)
+		for _, other := range modes {
+			if col := p.ColumnIn(line, other.mode); col != other.expected[2] {
+				t.Errorf("Expected col %d converted from %+v to %+v but got %d", other.expected[2], m.mode, other.mode, col)
+			}
+		}
+	}
+	_, err := NewTokenizer(strings.NewReader("\n\t\"😀\" + §"), "string", WithColumns(ColumnMode{UTF16: true, TabWidth: 8})).Tokenize()
+	if expected := "Error in file string: Unknown sumbol '§'\nAt line 2; col: 16"; err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q but got %v", expected, err)
+	}
+}
@@ -8,15 +8,18 @@ type TokenWalker interface {
 	Match(tokens ...TokenType) bool
 	Size() int
 	Clear()
+	FileSet() *FileSet
 }
 
 type walker struct {
+	fileSet  *FileSet
 	tokens   []Token
 	position int
 }
 
-func NewTokenWalker(tokens []Token) TokenWalker {
-	return &walker{tokens: tokens, position: 0}
+// Creates walker over tokens, positions of tokens are resolved by file set
+func NewTokenWalker(fileSet *FileSet, tokens []Token) TokenWalker {
+	return &walker{fileSet: fileSet, tokens: tokens, position: 0}
 }
 
 func (w walker) Next() bool {
@@ -96,6 +99,10 @@ func (w *walker) Clear() {
 	w.tokens = nil
 }
 
+func (w walker) FileSet() *FileSet {
+	return w.fileSet
+}
+
 func needCountOpens(needed TokenType) bool {
 	switch needed {
 	case TokenCloseBrace, TokenCloseBracket, TokenCloseParen:
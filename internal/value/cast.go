@@ -0,0 +1,74 @@
+package value
+
+import "strings"
+
+// Casts value to number: logic gives 1 or 0, string is parsed
+// (not numerical string gives its logic value) and null is NaN
+func ToNumber(v Value) Number {
+	switch v := v.(type) {
+	case Number:
+		return v
+	case Logic:
+		if v {
+			return NewNumber(1)
+		}
+		return NewNumber(0)
+	case String:
+		if n := ParseNumber(strings.TrimSpace(string(v))); !n.IsNaN() {
+			return n
+		}
+		return ToNumber(ToLogic(v))
+	}
+	return NaN
+}
+
+// Casts value to logic: zero, NaN, empty string and null are false
+func ToLogic(v Value) Logic {
+	switch v := v.(type) {
+	case Logic:
+		return v
+	case Number:
+		return Logic(!v.IsNaN() && v.rat.Sign() != 0)
+	case String:
+		return v != ""
+	case Null:
+		return false
+	}
+	return true
+}
+
+// Casts value to string
+func ToString(v Value) String {
+	if s, ok := v.(String); ok {
+		return s
+	}
+	return String(v.String())
+}
+
+// Compares values for equality, left operand's type decides the cast:
+// strings are compared case insensitively, anything else is compared as numbers
+func Equals(left, right Value) bool {
+	switch l := left.(type) {
+	case String:
+		return strings.EqualFold(string(l), string(ToString(right)))
+	case Null:
+		return right.Type() == TypeNull
+	case Number, Logic:
+		c, ok := Compare(left, right)
+		return ok && c == 0
+	}
+	// functions are equal only to themselves
+	return left == right
+}
+
+// Compares values, ok is false if values are not comparable (NaN is involved)
+func Compare(left, right Value) (c int, ok bool) {
+	if l, isString := left.(String); isString {
+		return strings.Compare(strings.ToLower(string(l)), strings.ToLower(string(ToString(right)))), true
+	}
+	a, b := ToNumber(left), ToNumber(right)
+	if a.IsNaN() || b.IsNaN() {
+		return 0, false
+	}
+	return a.rat.Cmp(b.rat), true
+}
@@ -0,0 +1,108 @@
+package value
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/Allexy/fishes/internal/lang"
+)
+
+var ErrDivisionByZero = errors.New("division by zero")
+
+// Evaluates binary operator over already evaluated operands
+// (runtime short-circuits && and || before calling it)
+func Binary(op string, left, right Value) (Value, error) {
+	switch op {
+	case lang.OpAnd:
+		return ToLogic(left) && ToLogic(right), nil
+	case lang.OpOr:
+		return ToLogic(left) || ToLogic(right), nil
+	case lang.OpEquals:
+		return Logic(Equals(left, right)), nil
+	case lang.OpNotEquals:
+		return Logic(!Equals(left, right)), nil
+	case lang.OpLesserThan, lang.OpLesserThanOrEquals, lang.OpGreaterThan, lang.OpGreaterThanOrEquals:
+		return relational(op, left, right), nil
+	}
+	return Arithmetic(op, left, right)
+}
+
+// Evaluates prefix operator
+func Unary(op string, v Value) (Value, error) {
+	switch op {
+	case lang.OpNot:
+		return !ToLogic(v), nil
+	case lang.OpMinus:
+		return Neg(ToNumber(v)), nil
+	case lang.OpPlus:
+		return ToNumber(v), nil
+	}
+	return nil, fmt.Errorf("unknown unary operator %q", op)
+}
+
+// Comparison with NaN is always false
+func relational(op string, left, right Value) Logic {
+	c, ok := Compare(left, right)
+	if !ok {
+		return false
+	}
+	switch op {
+	case lang.OpLesserThan:
+		return c < 0
+	case lang.OpLesserThanOrEquals:
+		return c <= 0
+	case lang.OpGreaterThan:
+		return c > 0
+	}
+	return c >= 0
+}
+
+// Evaluates arithmetic operator, "+" concatenates if left operand is a string,
+// otherwise both operands are cast to numbers
+func Arithmetic(op string, left, right Value) (Value, error) {
+	if l, ok := left.(String); ok && op == lang.OpPlus {
+		return l + ToString(right), nil
+	}
+	a, b := ToNumber(left), ToNumber(right)
+	switch op {
+	case lang.OpPlus:
+		return Add(a, b), nil
+	case lang.OpMinus:
+		return Add(a, Neg(b)), nil
+	case lang.OpMultiply:
+		if a.IsNaN() || b.IsNaN() {
+			return NaN, nil
+		}
+		return Number{new(big.Rat).Mul(a.rat, b.rat)}, nil
+	case lang.OpDivision, lang.OpModulo:
+		if a.IsNaN() || b.IsNaN() {
+			return NaN, nil
+		}
+		if b.rat.Sign() == 0 {
+			return nil, ErrDivisionByZero
+		}
+		q := new(big.Rat).Quo(a.rat, b.rat)
+		if op == lang.OpDivision {
+			return Number{q}, nil
+		}
+		// a - b * trunc(a / b)
+		t := new(big.Rat).SetInt(new(big.Int).Quo(q.Num(), q.Denom()))
+		return Number{new(big.Rat).Sub(a.rat, t.Mul(t, b.rat))}, nil
+	}
+	return nil, fmt.Errorf("unknown arithmetic operator %q", op)
+}
+
+func Add(a, b Number) Number {
+	if a.IsNaN() || b.IsNaN() {
+		return NaN
+	}
+	return Number{new(big.Rat).Add(a.rat, b.rat)}
+}
+
+func Neg(n Number) Number {
+	if n.IsNaN() {
+		return NaN
+	}
+	return Number{new(big.Rat).Neg(n.rat)}
+}
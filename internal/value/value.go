@@ -0,0 +1,143 @@
+package value
+
+import (
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/Allexy/fishes/internal/lang"
+)
+
+type Type uint8
+
+// Possible value types
+const (
+	TypeNull Type = iota
+	TypeNumber
+	TypeString
+	TypeLogic
+	TypeFunction
+)
+
+// All values of the language implement Value interface,
+// functions are implemented by the runtime
+type Value interface {
+	Type() Type
+	String() string
+}
+
+// Built-in NULL value, also received by omitted arguments
+type Null struct{}
+
+// Numbers are exact rationals, nil means NaN
+type Number struct {
+	rat *big.Rat
+}
+
+type String string
+
+type Logic bool
+
+var (
+	NullValue = Null{}
+	NaN       = Number{}
+)
+
+var decimalNumber = regexp.MustCompile(`^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]+)?$`)
+
+// Creates number from decimal text, NaN is returned if text is not a number
+func ParseNumber(text string) Number {
+	if !decimalNumber.MatchString(text) {
+		return NaN
+	}
+	r, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return NaN
+	}
+	return Number{r}
+}
+
+// Creates number from copy of rational, NaN is returned for nil
+func NewRational(r *big.Rat) Number {
+	if r == nil {
+		return NaN
+	}
+	return Number{new(big.Rat).Set(r)}
+}
+
+func NewNumber(n int64) Number {
+	return Number{new(big.Rat).SetInt64(n)}
+}
+
+// Returns copy of underlying rational, nil for NaN
+func (n Number) Rat() *big.Rat {
+	if n.IsNaN() {
+		return nil
+	}
+	return new(big.Rat).Set(n.rat)
+}
+
+func (Null) Type() Type     { return TypeNull }
+func (Number) Type() Type   { return TypeNumber }
+func (String) Type() Type   { return TypeString }
+func (Logic) Type() Type    { return TypeLogic }
+func (Null) String() string { return "" }
+
+func (n Number) IsNaN() bool {
+	return n.rat == nil
+}
+
+// Formats number as shortest decimal, periodic fractions are rounded to 16 digits
+func (n Number) String() string {
+	if n.IsNaN() {
+		return "NaN"
+	}
+	if n.rat.IsInt() {
+		return n.rat.Num().String()
+	}
+	digits, exact := decimalDigits(n.rat.Denom())
+	if !exact {
+		digits = 16
+	}
+	s := n.rat.FloatString(digits)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+func (s String) String() string {
+	return string(s)
+}
+
+func (l Logic) String() string {
+	if l {
+		return lang.KwTrue
+	}
+	return lang.KwFalse
+}
+
+// Returns count of fraction digits needed to represent 1/denom exactly,
+// exact is false if such representation is infinite
+func decimalDigits(denom *big.Int) (digits int, exact bool) {
+	d := new(big.Int).Set(denom)
+	m := new(big.Int)
+	two, five := big.NewInt(2), big.NewInt(5)
+	twos, fives := 0, 0
+	for {
+		if q, r := new(big.Int).QuoRem(d, two, m); r.Sign() == 0 {
+			d, twos = q, twos+1
+			continue
+		}
+		if q, r := new(big.Int).QuoRem(d, five, m); r.Sign() == 0 {
+			d, fives = q, fives+1
+			continue
+		}
+		break
+	}
+	if d.Cmp(big.NewInt(1)) != 0 {
+		return 0, false
+	}
+	if twos > fives {
+		return twos, true
+	}
+	return fives, true
+}
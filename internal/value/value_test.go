@@ -0,0 +1,244 @@
+package value
+
+import (
+	"testing"
+
+	"github.com/Allexy/fishes/internal/lang"
+)
+
+// Shortcut for numbers created from decimal text
+func _n(text string) Number {
+	n := ParseNumber(text)
+	if n.IsNaN() {
+		panic("invalid number " + text)
+	}
+	return n
+}
+
+type _equalityCase struct {
+	left, right Value
+	expected    bool
+}
+
+func _testEquality(t *testing.T, cases []_equalityCase) {
+	t.Helper()
+	for _, c := range cases {
+		if actual := Equals(c.left, c.right); actual != c.expected {
+			t.Errorf("Expected %#v == %#v to be %v", c.left, c.right, c.expected)
+		}
+	}
+}
+
+// Rule: when left operand is a string, right one is cast to string (test_3, test_14)
+func TestLeftStringCastsRightToString(t *testing.T) {
+	_testEquality(t, []_equalityCase{
+		{String("1"), _n("1"), true},
+		{String("1.5"), _n("1.50"), true},
+		{String("1.0"), _n("1"), false},
+		{String("true"), Logic(true), true},
+		{String("false"), Logic(false), true},
+		{String("1"), Logic(true), false},
+		{String(""), NullValue, true},
+		{String("null"), NullValue, false},
+	})
+}
+
+// Rule: string comparison is case insensitive and unicode aware (test_8, test_9, test_10)
+func TestStringComparisonIgnoresCase(t *testing.T) {
+	_testEquality(t, []_equalityCase{
+		{String("String"), String("String"), true},
+		{String("StRiNg"), String("sTrInG"), true},
+		{String("это текст UTF8"), String("ЭТО ТЕКСТ utf8"), true},
+		{String("Straße"), String("STRASSE"), false},
+		{String("abc"), String("abd"), false},
+	})
+	c, ok := Compare(String("ABC"), String("abd"))
+	if !ok || c >= 0 {
+		t.Errorf("Expected \"ABC\" < \"abd\" but got %d", c)
+	}
+}
+
+// Rule: when left operand is a number, right one is cast to number (test_5)
+func TestLeftNumberCastsRightToNumber(t *testing.T) {
+	_testEquality(t, []_equalityCase{
+		{_n("1"), String("1"), true},
+		{_n("1"), String(" 1.0 "), true},
+		{_n("1"), Logic(true), true},
+		{_n("0"), Logic(false), true},
+		{_n("1"), String("not a number"), true},
+		{_n("0"), String(""), true},
+		{_n("0"), NullValue, false},
+		{NaN, NaN, false},
+	})
+}
+
+// Rule: logic operands are compared as numbers, true is 1 and false is 0 (test_12, test_13, test_16, test_17)
+func TestLeftLogicCastsToNumbers(t *testing.T) {
+	_testEquality(t, []_equalityCase{
+		{Logic(true), Logic(true), true},
+		{Logic(false), Logic(false), true},
+		{Logic(true), String("1"), true},
+		{Logic(true), String("not empty"), true},
+		{Logic(false), String(""), true},
+		{Logic(false), _n("0"), true},
+		{Logic(true), _n("1"), true},
+		{Logic(true), _n("2"), false},
+	})
+}
+
+// Rule: false is 0 but null is NaN, so they are not equal (test_15, test_empty_expression)
+func TestNullIsNaN(t *testing.T) {
+	_testEquality(t, []_equalityCase{
+		{Logic(false), NullValue, false},
+		{_n("0"), NullValue, false},
+		{NullValue, NullValue, true},
+		{NullValue, String(""), false},
+		{NullValue, Logic(false), false},
+	})
+	if !ToNumber(NullValue).IsNaN() {
+		t.Error("Expected null to be cast to NaN")
+	}
+}
+
+// Rule: numbers are exact, so rounding errors of binary floats do not appear
+func TestNumbersAreExact(t *testing.T) {
+	p, _ := Arithmetic(lang.OpMultiply, _n("0.1"), _n("0.1"))
+	p, _ = Arithmetic(lang.OpMultiply, p, _n("0.1"))
+	_testEquality(t, []_equalityCase{
+		{p, _n("0.001"), true},
+		{_n("1.123456"), _n("1.12345"), false},
+	})
+}
+
+func TestToNumber(t *testing.T) {
+	cases := []struct {
+		v        Value
+		expected string
+	}{
+		{_n("2.50"), "2.5"},
+		{Logic(true), "1"},
+		{Logic(false), "0"},
+		{String("-12.5"), "-12.5"},
+		{String("1e3"), "1000"},
+		{String("1/2"), "1"},
+		{String("0x10"), "1"},
+		{String("abc"), "1"},
+		{String(""), "0"},
+		{NullValue, "NaN"},
+	}
+	for _, c := range cases {
+		if actual := ToNumber(c.v).String(); actual != c.expected {
+			t.Errorf("Expected %#v cast to number %q but got %q", c.v, c.expected, actual)
+		}
+	}
+}
+
+func TestToLogic(t *testing.T) {
+	cases := []struct {
+		v        Value
+		expected Logic
+	}{
+		{_n("0"), false},
+		{_n("0.5"), true},
+		{_n("-1"), true},
+		{NaN, false},
+		{String(""), false},
+		{String("false"), true},
+		{NullValue, false},
+	}
+	for _, c := range cases {
+		if actual := ToLogic(c.v); actual != c.expected {
+			t.Errorf("Expected %#v cast to logic %v but got %v", c.v, c.expected, actual)
+		}
+	}
+}
+
+func TestToString(t *testing.T) {
+	cases := []struct {
+		v        Value
+		expected String
+	}{
+		{_n("1"), "1"},
+		{_n("-16"), "-16"},
+		{_n("1.123456"), "1.123456"},
+		{_n(".5"), "0.5"},
+		{_n("1e-9"), "0.000000001"},
+		{Logic(true), "true"},
+		{Logic(false), "false"},
+		{NullValue, ""},
+		{NaN, "NaN"},
+	}
+	for _, c := range cases {
+		if actual := ToString(c.v); actual != c.expected {
+			t.Errorf("Expected %#v cast to string %q but got %q", c.v, c.expected, actual)
+		}
+	}
+	third, _ := Arithmetic(lang.OpDivision, _n("1"), _n("3"))
+	if third.String() != "0.3333333333333333" {
+		t.Errorf("Expected periodic fraction rounded to 16 digits but got %q", third.String())
+	}
+}
+
+func TestBinary(t *testing.T) {
+	cases := []struct {
+		op          string
+		left, right Value
+		expected    string
+	}{
+		{lang.OpPlus, String("xyz"), _n("1"), "xyz1"},
+		{lang.OpPlus, _n("1"), String("2"), "3"},
+		{lang.OpPlus, _n("1"), NullValue, "NaN"},
+		{lang.OpMinus, _n("1"), _n("3"), "-2"},
+		{lang.OpMultiply, Logic(true), _n("3"), "3"},
+		{lang.OpDivision, _n("2.0"), _n("4.0"), "0.5"},
+		{lang.OpModulo, _n("-7"), _n("3"), "-1"},
+		{lang.OpModulo, _n("7.5"), _n("2"), "1.5"},
+		{lang.OpLesserThan, _n("1"), _n("2"), "true"},
+		{lang.OpGreaterThanOrEquals, _n("2"), _n("2"), "true"},
+		{lang.OpGreaterThan, _n("1"), NullValue, "false"},
+		{lang.OpLesserThan, NullValue, _n("1"), "false"},
+		{lang.OpGreaterThan, String("xyz"), _n("1"), "true"},
+		{lang.OpAnd, Logic(true), String(""), "false"},
+		{lang.OpOr, NullValue, _n("1"), "true"},
+		{lang.OpNotEquals, Logic(false), NullValue, "true"},
+	}
+	for _, c := range cases {
+		res, err := Binary(c.op, c.left, c.right)
+		if err != nil {
+			t.Errorf("Unexpected error for %#v %s %#v: %v", c.left, c.op, c.right, err)
+			continue
+		}
+		if res.String() != c.expected {
+			t.Errorf("Expected %#v %s %#v to be %q but got %q", c.left, c.op, c.right, c.expected, res.String())
+		}
+	}
+	for _, op := range []string{lang.OpDivision, lang.OpModulo} {
+		if _, err := Binary(op, _n("1"), _n("0")); err != ErrDivisionByZero {
+			t.Errorf("Expected division by zero error for %q but got %v", op, err)
+		}
+	}
+}
+
+func TestUnary(t *testing.T) {
+	cases := []struct {
+		op       string
+		v        Value
+		expected string
+	}{
+		{lang.OpNot, Logic(false), "true"},
+		{lang.OpNot, _n("1"), "false"},
+		{lang.OpNot, NullValue, "true"},
+		{lang.OpMinus, String("2"), "-2"},
+		{lang.OpPlus, Logic(true), "1"},
+	}
+	for _, c := range cases {
+		res, err := Unary(c.op, c.v)
+		if err != nil {
+			t.Errorf("Unexpected error for %s%#v: %v", c.op, c.v, err)
+			continue
+		}
+		if res.String() != c.expected {
+			t.Errorf("Expected %s%#v to be %q but got %q", c.op, c.v, c.expected, res.String())
+		}
+	}
+}
@@ -1,30 +1,85 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
 	"os"
+)
 
-	"github.com/Allexy/fishes/internal/tokenizer"
+// Exit codes
+const (
+	exitOK      = 0
+	exitFailure = 1 // invalid source, uncaught exception or failed tests
+	exitUsage   = 2 // invalid arguments or unreadable files
 )
 
+const usage = `Usage: fishes <command> [flags] sources...
+
+Commands:
+  tokens  print tokens of sources
+  parse   print syntax tree of sources
+  run     run scripts
+  test    run test functions of scripts
+  fmt     print formatted sources
+  check   check syntax of sources
+
+Sources are files, directories (all .fs files inside), glob patterns or "-" for standard input.
+Run "fishes <command> -h" for flags of command.
+`
+
+type command func(c *cli, args []string) int
+
+var commands = map[string]command{
+	"tokens": (*cli).tokensCommand,
+	"parse":  (*cli).parseCommand,
+	"run":    (*cli).runCommand,
+	"test":   (*cli).testCommand,
+	"fmt":    (*cli).fmtCommand,
+	"check":  (*cli).checkCommand,
+}
+
+type cli struct {
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+	sources map[string][]byte // text of read sources by name
+}
+
+func newCLI(stdin io.Reader, stdout, stderr io.Writer) *cli {
+	return &cli{stdin: stdin, stdout: stdout, stderr: stderr, sources: make(map[string][]byte, 8)}
+}
+
 func main() {
-	fmt.Println("Test example")
-	sourceName := "self_test.fs"
-	f, err := os.Open(sourceName)
-	if err != nil {
-		panic(err)
+	os.Exit(newCLI(os.Stdin, os.Stdout, os.Stderr).main(os.Args[1:]))
+}
+
+// Runs command given by arguments and returns exit code
+func (c *cli) main(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(c.stderr, usage)
+		return exitUsage
+	}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(c.stdout, usage)
+		return exitOK
 	}
-	defer f.Close()
-	tzr := tokenizer.NewTokenizer(f, sourceName)
-	if tokens, err := tzr.Tokenize(); err == nil {
-		for tokens.Next() {
-			t := tokens.Get(0)
-			fmt.Println(t)
-			tokens.Move(1)
-		}
-	} else {
-		panic(err)
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(c.stderr, "Unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
 	}
+	return cmd(c, args[1:])
+}
 
-	fmt.Println("DONE")
+// Creates flag set of command, its errors are written to stderr
+func (c *cli) flags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: fishes %s [flags] sources...\n", name)
+		flags.PrintDefaults()
+	}
+	return flags
 }
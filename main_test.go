@@ -0,0 +1,105 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// Runs command with given standard input, returns exit code, stdout and stderr
+func _cli(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr strings.Builder
+	code := newCLI(strings.NewReader(stdin), &stdout, &stderr).main(args)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestUsage(t *testing.T) {
+	if code, _, stderr := _cli(""); code != exitUsage || !strings.Contains(stderr, "Usage:") {
+		t.Errorf("Expected usage but got %d %q", code, stderr)
+	}
+	if code, _, _ := _cli("", "unknown"); code != exitUsage {
+		t.Errorf("Expected exit code %d for unknown command but got %d", exitUsage, code)
+	}
+	if code, _, _ := _cli("", "check", "missing.fs"); code != exitUsage {
+		t.Errorf("Expected exit code %d for missing file but got %d", exitUsage, code)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	code, stdout, stderr := _cli("$a = 1;\n\t$b = 1 ^ 2;\n", "check", "-")
+	expected := "<stdin>:2:9: Unknown sumbol '^'\n    \t$b = 1 ^ 2;\n    \t       ^\n"
+	if code != exitFailure || stdout != "" || stderr != expected {
+		t.Errorf("Unexpected result %d %q %q", code, stdout, stderr)
+	}
+	if code, _, stderr := _cli("func f() { = 1; }", "check", "-"); code != exitOK || stderr != "" {
+		t.Errorf("Unexpected result %d %q", code, stderr)
+	}
+}
+
+func TestRun(t *testing.T) {
+	code, stdout, _ := _cli("print(\"hello\");", "run", "-")
+	if code != exitOK || stdout != "hello\n" {
+		t.Errorf("Unexpected result %d %q", code, stdout)
+	}
+	code, _, stderr := _cli("func f() {\n  = 1 / 0;\n}\nf();\n", "run", "-")
+	expected := "<stdin>:2:7: DivisionByZero (code 3): Division by zero\n" +
+		"      = 1 / 0;\n" +
+		"          ^\n" +
+		"\tat f (<stdin>:2:7)\n" +
+		"\tat main (<stdin>:4:1)\n"
+	if code != exitFailure || stderr != expected {
+		t.Errorf("Unexpected result %d %q", code, stderr)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	cases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"tokens", "-"}, "BOF(\"\"@0:0)\nVARIABLE(\"a\"@1:1)\nASSIGNMENT(\"=\"@1:5)\nNUMBER(\"1\"@1:8)\nSEMICOLON(\";\"@1:9)\nEOF(\"\"@1:9)\n"},
+		{[]string{"tokens", "-trivia", "-"}, "BOF(\"\"@0:0)\nVARIABLE(\"a\"@1:1)\nWHITE_SPACE(\"  \"@1:3)\nASSIGNMENT(\"=\"@1:5)\nWHITE_SPACE(\"  \"@1:6)\nNUMBER(\"1\"@1:8)\nSEMICOLON(\";\"@1:9)\nEOF(\"\"@1:9)\n"},
+		{[]string{"parse", "-"}, "Program <stdin>:1:1\n  ExprStmt <stdin>:1:1\n    AssignExpr = <stdin>:1:1\n      Variable $a <stdin>:1:1\n      NumberLit 1 <stdin>:1:8\n"},
+		{[]string{"fmt", "-"}, "$a = 1;\n"},
+	}
+	for _, c := range cases {
+		code, stdout, stderr := _cli("$a  =  1;", c.args...)
+		if code != exitOK || stdout != c.expected {
+			t.Errorf("Unexpected result of %v: %d %q %q", c.args, code, stdout, stderr)
+		}
+	}
+}
+
+func TestTestCommand(t *testing.T) {
+	code, stdout, _ := _cli("", "test", "-run", "^test_1[0-2]$", "self_test.fs")
+	if code != exitOK || !strings.HasSuffix(stdout, "[i] Passed: 3, failed: 0\n") {
+		t.Errorf("Unexpected result %d %q", code, stdout)
+	}
+	code, stdout, _ = _cli("func testA() { = false; }", "test", "-format", "tap", "-")
+	if code != exitFailure || !strings.Contains(stdout, "not ok 1 - testA") {
+		t.Errorf("Unexpected result %d %q", code, stdout)
+	}
+	if code, _, _ := _cli("", "test", "-format", "html", "self_test.fs"); code != exitUsage {
+		t.Errorf("Expected exit code %d for unknown format but got %d", exitUsage, code)
+	}
+}
+
+func TestFmtKeepsComments(t *testing.T) {
+	source := "# header\n#@\"desc\"\nfunc testA() {\n    $a = 1; # trailing\n    /* block\n       comment */\n    = $a;\n    # before end\n} # after\n// last\n"
+	code, stdout, stderr := _cli(source, "fmt", "-")
+	if code != exitOK || stdout != source {
+		t.Errorf("Unexpected result %d %q %q", code, stdout, stderr)
+	}
+}
+
+func TestEmptySource(t *testing.T) {
+	for _, source := range []string{"", "  \n", "# only comment\n"} {
+		for _, command := range []string{"check", "run", "fmt", "test"} {
+			if code, _, stderr := _cli(source, command, "-"); code != exitOK || stderr != "" {
+				t.Errorf("Unexpected result of %s for %q: %d %q", command, source, code, stderr)
+			}
+		}
+	}
+	if code, stdout, _ := _cli("# only comment\n", "fmt", "-"); code != exitOK || stdout != "# only comment\n" {
+		t.Errorf("Unexpected result %d %q", code, stdout)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Argument which means standard input
+const stdinArg = "-"
+
+// Name of source read from standard input
+const stdinName = "<stdin>"
+
+type source struct {
+	name string
+	data []byte
+}
+
+// Reads sources given as files, directories (all .fs files inside), glob patterns or "-" for standard input
+func (c *cli) readSources(args []string) ([]source, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no source files given")
+	}
+	var sources []source
+	for _, arg := range args {
+		if arg == stdinArg {
+			data, err := io.ReadAll(c.stdin)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, c.addSource(stdinName, data))
+			continue
+		}
+		names, err := expand(arg)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			data, err := os.ReadFile(name)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, c.addSource(name, data))
+		}
+	}
+	return sources, nil
+}
+
+// Remembers source text so diagnostics can show the line with error
+func (c *cli) addSource(name string, data []byte) source {
+	c.sources[name] = data
+	return source{name: name, data: data}
+}
+
+// Returns files matching argument
+func expand(arg string) ([]string, error) {
+	if strings.ContainsAny(arg, "*?[") {
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %q", arg)
+		}
+		return matches, nil
+	}
+	info, err := os.Stat(arg)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{arg}, nil
+	}
+	matches, err := filepath.Glob(filepath.Join(arg, "*.fs"))
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no .fs files in directory %s", arg)
+	}
+	return matches, nil
+}
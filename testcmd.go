@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+
+	"github.com/Allexy/fishes/internal/testrunner"
+)
+
+// fishes test [-run regexp] [-format format] sources...
+func (c *cli) testCommand(args []string) int {
+	flags := c.flags("test")
+	pattern := flags.String("run", "", "run only tests with names matching `regexp`")
+	format := flags.String("format", testrunner.FormatText, "report `format`: text, junit, tap or json")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	var filter *regexp.Regexp
+	if *pattern != "" {
+		var err error
+		if filter, err = regexp.Compile(*pattern); err != nil {
+			fmt.Fprintf(c.stderr, "Invalid -run pattern: %v\n", err)
+			return exitUsage
+		}
+	}
+	reporter, err := testrunner.NewReporter(*format, c.stdout)
+	if err != nil {
+		c.printError(err)
+		return exitUsage
+	}
+	sources, err := c.readSources(flags.Args())
+	if err != nil {
+		c.printError(err)
+		return exitUsage
+	}
+	runner := testrunner.NewRunner(filter)
+	failed := 0
+	for _, src := range sources {
+		suite, err := testrunner.Discover(bytes.NewReader(src.data), src.name)
+		if err != nil {
+			c.printError(err)
+			return exitFailure
+		}
+		for _, res := range runner.Run(suite) {
+			if !res.Passed {
+				failed++
+			}
+			if err := reporter.Report(res); err != nil {
+				c.printError(err)
+				return exitUsage
+			}
+		}
+	}
+	if err := reporter.Finish(); err != nil {
+		c.printError(err)
+		return exitUsage
+	}
+	if failed > 0 {
+		return exitFailure
+	}
+	return exitOK
+}